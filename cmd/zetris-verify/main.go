@@ -0,0 +1,60 @@
+// Команда zetris-verify воспроизводит записи партий без окна и проверяет их итог.
+// Она зависит только от движка, поэтому работает на сервере без дисплея:
+//
+//	zetris-verify запись.zr ...
+//
+// Для каждой записи печатаются счёт, линии, время и хеш итогового поля.
+// Код выхода 1, если хотя бы одна запись не сошлась или не прочиталась
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/Xu3is/Zetris/src/engine"
+	"os"
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Использование:\n  zetris-verify запись.zr ...\n")
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	code := 0
+	for _, path := range flag.Args() {
+		if verifyReplay(path) != 0 {
+			code = 1
+		}
+	}
+	os.Exit(code)
+}
+
+// verifyReplay воспроизводит запись партии и печатает её итог.
+// Возвращает код выхода: 0 — итог совпал с записанным, 1 — расхождение или ошибка
+func verifyReplay(path string) int {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	var replay engine.Replay
+	if err := replay.UnmarshalBinary(data); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+		return 1
+	}
+	result, err := replay.Verify()
+	if flag.NArg() > 1 {
+		fmt.Printf("%s:\n", path)
+	}
+	fmt.Printf("счёт: %d\nлинии: %d\nвремя: %s (%d кадров)\nполе: %s\n",
+		result.Score, result.Lines, engine.FramesToDuration(result.Frames), result.Frames, result.Board)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+		return 1
+	}
+	return 0
+}
@@ -3,6 +3,7 @@ package src
 import (
 	"bytes"
 	"fmt"
+	"github.com/Xu3is/Zetris/src/engine"
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/audio"
 	"github.com/hajimehoshi/ebiten/v2/audio/wav"
@@ -47,6 +48,36 @@ func (g *Game) loadAssets() error {
 		g.images["boardcell"] = img
 	}
 
+	// Загрузка изображения мусорных клеток
+	path = "src/assets/images/garbage.png"
+	if _, err := os.Stat(path); err == nil {
+		img, _, err := ebitenutil.NewImageFromFile(path)
+		if err != nil {
+			img = ebiten.NewImage(cellSize, cellSize)
+			img.Fill(color.RGBA{90, 90, 100, 255})
+		}
+		g.images[engine.GarbageKind] = img
+	} else {
+		img := ebiten.NewImage(cellSize, cellSize)
+		img.Fill(color.RGBA{90, 90, 100, 255})
+		g.images[engine.GarbageKind] = img
+	}
+
+	// Загрузка рамки для очереди следующих фигур
+	path = "src/assets/images/nextblock.png"
+	if _, err := os.Stat(path); err == nil {
+		img, _, err := ebitenutil.NewImageFromFile(path)
+		if err != nil {
+			img = ebiten.NewImage(cellSize*3, cellSize*3)
+			img.Fill(color.RGBA{60, 80, 110, 255})
+		}
+		g.images["nextblock"] = img
+	} else {
+		img := ebiten.NewImage(cellSize*3, cellSize*3)
+		img.Fill(color.RGBA{60, 80, 110, 255})
+		g.images["nextblock"] = img
+	}
+
 	// Загрузка шрифта Times New Roman
 	ttfPath := "src/assets/Times New Roman.ttf"
 	if _, err := os.Stat(ttfPath); err == nil {
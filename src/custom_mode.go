@@ -2,11 +2,13 @@ package src
 
 import (
 	"fmt"
+	"github.com/Xu3is/Zetris/src/engine"
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/hajimehoshi/ebiten/v2/text/v2"
 	"image/color"
-	"time"
+	"slices"
+	"strconv"
 )
 
 // CustomMode представляет пользовательский режим
@@ -16,16 +18,37 @@ type CustomMode struct {
 	selectedIndex int
 	isLimited     bool
 	speedLevel    int
+	linesPerLevel int // 0 — скорость не меняется
+	randomizer    int
+	seedInput     string // пустая строка — случайный сид
+	isHoldEnabled bool
+	lockPolicy    int
+	lockDelay     int // в кадрах
+	ruleset       int
 }
 
+const (
+	lockDelayStep = 5
+	lockDelayMax  = 120
+)
+
+// linesPerLevelOptions перечисляет варианты роста уровня, 0 — уровень не растёт
+var linesPerLevelOptions = []int{0, 5, 10, 15, 20}
+
 // NewCustomMode создает новый пользовательский режим
 func NewCustomMode(game *Game) *CustomMode {
 	return &CustomMode{
 		game:          game,
-		elements:      []string{"Ограничение линий", "Скорость", "Начать"},
+		elements:      []string{"Ограничение линий", "Скорость", "Рост уровня", "Генератор", "Сид", "Запас", "Фиксация", "Задержка фиксации", "Правила", "Начать"},
 		selectedIndex: 0,
 		isLimited:     false,
-		speedLevel:    0,
+		speedLevel:    1,
+		linesPerLevel: 0,
+		randomizer:    0,
+		isHoldEnabled: true,
+		lockPolicy:    0,
+		lockDelay:     30,
+		ruleset:       0,
 	}
 }
 
@@ -44,48 +67,137 @@ func (cm *CustomMode) Update() error {
 		}
 	}
 
-	if cm.selectedIndex == 0 { // Ограничение линий
+	element := cm.elements[cm.selectedIndex]
+
+	if element == "Ограничение линий" {
 		if inpututil.IsKeyJustPressed(ebiten.KeyArrowLeft) || inpututil.IsKeyJustPressed(ebiten.KeyArrowRight) {
 			cm.isLimited = !cm.isLimited
 		}
 	}
 
-	if cm.selectedIndex == 1 { // Скорость
+	if element == "Скорость" {
 		if inpututil.IsKeyJustPressed(ebiten.KeyArrowLeft) {
 			cm.speedLevel--
-			if cm.speedLevel < 0 {
-				cm.speedLevel = len(speedLevels) - 1
+			if cm.speedLevel < 1 {
+				cm.speedLevel = len(engine.GravityCurve)
 			}
 		}
 		if inpututil.IsKeyJustPressed(ebiten.KeyArrowRight) {
 			cm.speedLevel++
-			if cm.speedLevel >= len(speedLevels) {
-				cm.speedLevel = 0
+			if cm.speedLevel > len(engine.GravityCurve) {
+				cm.speedLevel = 1
+			}
+		}
+	}
+
+	if element == "Рост уровня" {
+		i := slices.Index(linesPerLevelOptions, cm.linesPerLevel)
+		if inpututil.IsKeyJustPressed(ebiten.KeyArrowLeft) && i > 0 {
+			cm.linesPerLevel = linesPerLevelOptions[i-1]
+		}
+		if inpututil.IsKeyJustPressed(ebiten.KeyArrowRight) && i < len(linesPerLevelOptions)-1 {
+			cm.linesPerLevel = linesPerLevelOptions[i+1]
+		}
+	}
+
+	if element == "Генератор" {
+		if inpututil.IsKeyJustPressed(ebiten.KeyArrowLeft) {
+			cm.randomizer--
+			if cm.randomizer < 0 {
+				cm.randomizer = len(engine.RandomizerKinds) - 1
+			}
+		}
+		if inpututil.IsKeyJustPressed(ebiten.KeyArrowRight) {
+			cm.randomizer++
+			if cm.randomizer >= len(engine.RandomizerKinds) {
+				cm.randomizer = 0
+			}
+		}
+	}
+
+	if element == "Сид" {
+		for _, key := range inpututil.AppendJustPressedKeys(nil) {
+			if key >= ebiten.KeyDigit0 && key <= ebiten.KeyDigit9 {
+				// Цифра, после которой сид не помещается в 64 бита, не принимается
+				input := cm.seedInput + string(rune('0'+key-ebiten.KeyDigit0))
+				if _, err := strconv.ParseUint(input, 10, 64); err == nil {
+					cm.seedInput = input
+				}
+			}
+			if key == ebiten.KeyBackspace && len(cm.seedInput) > 0 {
+				cm.seedInput = cm.seedInput[:len(cm.seedInput)-1]
+			}
+		}
+	}
+
+	if element == "Запас" {
+		if inpututil.IsKeyJustPressed(ebiten.KeyArrowLeft) || inpututil.IsKeyJustPressed(ebiten.KeyArrowRight) {
+			cm.isHoldEnabled = !cm.isHoldEnabled
+		}
+	}
+
+	if element == "Фиксация" {
+		if inpututil.IsKeyJustPressed(ebiten.KeyArrowLeft) {
+			cm.lockPolicy--
+			if cm.lockPolicy < 0 {
+				cm.lockPolicy = len(engine.LockPolicies) - 1
+			}
+		}
+		if inpututil.IsKeyJustPressed(ebiten.KeyArrowRight) {
+			cm.lockPolicy++
+			if cm.lockPolicy >= len(engine.LockPolicies) {
+				cm.lockPolicy = 0
 			}
 		}
 	}
 
-	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) && cm.selectedIndex == 2 {
+	if element == "Задержка фиксации" {
+		if inpututil.IsKeyJustPressed(ebiten.KeyArrowLeft) && cm.lockDelay > lockDelayStep {
+			cm.lockDelay -= lockDelayStep
+		}
+		if inpututil.IsKeyJustPressed(ebiten.KeyArrowRight) && cm.lockDelay < lockDelayMax {
+			cm.lockDelay += lockDelayStep
+		}
+	}
+
+	if element == "Правила" {
+		if inpututil.IsKeyJustPressed(ebiten.KeyArrowLeft) {
+			cm.ruleset--
+			if cm.ruleset < 0 {
+				cm.ruleset = len(engine.Rulesets) - 1
+			}
+		}
+		if inpututil.IsKeyJustPressed(ebiten.KeyArrowRight) {
+			cm.ruleset++
+			if cm.ruleset >= len(engine.Rulesets) {
+				cm.ruleset = 0
+			}
+		}
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) && element == "Начать" {
 		if !cm.game.customNameEntered {
 			cm.game.state = StateEnterName
 			cm.game.enterName.nextState = StateGame
 			cm.game.enterName.isCustomMode = true
 			return nil
 		}
-		cm.game.grid = [gridHeight][gridWidth]string{}
-		cm.game.currentPiece = cm.game.newPiece()
-		cm.game.nextPiece = cm.game.newPiece()
-		cm.game.score = 0
-		cm.game.fallSpeed = speedLevels[cm.speedLevel].fallSpeed
-		cm.game.isGameOver = false
-		cm.game.isPaused = false
-		cm.game.isPieceGrounded = false
-		cm.game.keyLastAction = make(map[ebiten.Key]time.Time)
-		cm.game.keyPressStart = make(map[ebiten.Key]time.Time)
-		cm.game.isLimitedTo40Lines = cm.isLimited
-		cm.game.isCustomSpeed = true
-		cm.game.clearedLines = 0
-		cm.game.state = StateGame
+		seed, err := strconv.ParseUint(cm.seedInput, 10, 64)
+		cm.game.customSeed = seed
+		cm.game.isCustomSeedFixed = err == nil
+		config := engine.Config{
+			Level:         cm.speedLevel,
+			LinesPerLevel: cm.linesPerLevel,
+			Randomizer:    engine.RandomizerKinds[cm.randomizer],
+			DisableHold:   !cm.isHoldEnabled,
+			LockPolicy:    engine.LockPolicies[cm.lockPolicy],
+			LockDelay:     cm.lockDelay,
+			Ruleset:       engine.Rulesets[cm.ruleset],
+		}
+		if cm.isLimited {
+			config.LineGoal = 40
+		}
+		cm.game.startCustom(config)
 	}
 
 	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
@@ -108,12 +220,12 @@ func (cm *CustomMode) Draw(screen *ebiten.Image) {
 			Size:   25, // Увеличиваем размер шрифта для заголовка
 		}
 		headerText := "Пользовательский режим"
-		headerY := ScreenHeight/2 - 100 // Аналогично highscore.go (Y=200)
+		headerY := ScreenHeight/2 - 220
 		drawText(screen, headerText, ScreenWidth/2-100, headerY, color.RGBA{180, 220, 255, 255}, headerFont, false)
 
 		// Отрисовка элементов меню под заголовком
 		for i, element := range cm.elements {
-			y := headerY + 80 + i*40 // Начинаем с отступа 40 пикселей от заголовка (Y=240, 280, 320)
+			y := headerY + 60 + i*40 // Начинаем с отступа 60 пикселей от заголовка
 			var clr color.Color = color.RGBA{180, 220, 255, 255}
 			if i == cm.selectedIndex {
 				clr = color.RGBA{100, 200, 255, 255}
@@ -127,7 +239,33 @@ func (cm *CustomMode) Draw(screen *ebiten.Image) {
 					text = "Ограничение линий: Нет"
 				}
 			case "Скорость":
-				text = fmt.Sprintf("Скорость: Уровень %d", speedLevels[cm.speedLevel].level)
+				text = fmt.Sprintf("Скорость: Уровень %d", cm.speedLevel)
+			case "Рост уровня":
+				if cm.linesPerLevel == 0 {
+					text = "Рост уровня: Нет"
+				} else {
+					text = fmt.Sprintf("Рост уровня: каждые %d линий", cm.linesPerLevel)
+				}
+			case "Генератор":
+				text = fmt.Sprintf("Генератор: %s", engine.RandomizerKinds[cm.randomizer])
+			case "Сид":
+				if cm.seedInput == "" {
+					text = "Сид: Случайный"
+				} else {
+					text = fmt.Sprintf("Сид: %s", cm.seedInput)
+				}
+			case "Запас":
+				if cm.isHoldEnabled {
+					text = "Запас: Вкл"
+				} else {
+					text = "Запас: Выкл"
+				}
+			case "Фиксация":
+				text = fmt.Sprintf("Фиксация: %s", engine.LockPolicies[cm.lockPolicy])
+			case "Задержка фиксации":
+				text = fmt.Sprintf("Задержка фиксации: %d мс", engine.FramesToDuration(cm.lockDelay).Milliseconds())
+			case "Правила":
+				text = fmt.Sprintf("Правила: %s", engine.Rulesets[cm.ruleset])
 			case "Начать":
 				text = "Начать"
 			}
@@ -0,0 +1,143 @@
+package engine
+
+const (
+	Width  = 10
+	Height = 20
+)
+
+// Board представляет игровое поле. Пустая клетка хранится как "",
+// занятая — как тип фигуры, которая её заполнила
+type Board [Height][Width]string
+
+// Cell возвращает содержимое клетки или "" для координат вне поля
+func (b *Board) Cell(x, y int) string {
+	if x < 0 || x >= Width || y < 0 || y >= Height {
+		return ""
+	}
+	return b[y][x]
+}
+
+// collides проверяет, пересекается ли фигура со стенами, дном или занятыми клетками.
+// Клетки выше поля считаются свободными
+func (b *Board) collides(p *Piece) bool {
+	for i, row := range p.Shape() {
+		for j, cell := range row {
+			if cell == 0 {
+				continue
+			}
+			x, y := p.X+j, p.Y+i
+			if x < 0 || x >= Width || y >= Height || (y >= 0 && b[y][x] != "") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// place фиксирует фигуру на поле. Возвращает false, если часть фигуры
+// осталась выше поля (такие клетки отбрасываются)
+func (b *Board) place(p *Piece) bool {
+	inside := true
+	for i, row := range p.Shape() {
+		for j, cell := range row {
+			if cell == 0 {
+				continue
+			}
+			x, y := p.X+j, p.Y+i
+			if y < 0 {
+				inside = false
+				continue
+			}
+			b[y][x] = p.Kind
+		}
+	}
+	return inside
+}
+
+// clearLines удаляет заполненные строки, сдвигая всё, что выше, вниз.
+// Возвращает номера удалённых строк сверху вниз и сколько из них были мусорными
+func (b *Board) clearLines() (rows []int, garbage int) {
+	for i, row := range b {
+		filled := true
+		for _, cell := range row {
+			if cell == "" {
+				filled = false
+				break
+			}
+		}
+		if filled {
+			rows = append(rows, i)
+			if isGarbageRow(row) {
+				garbage++
+			}
+		}
+	}
+	removeRows((*[Height][Width]string)(b), rows)
+	return rows, garbage
+}
+
+// pushRow сдвигает поле на строку вверх и добавляет row снизу.
+// Возвращает false, если занятые клетки вытолкнуты за верх поля
+func (b *Board) pushRow(row [Width]string) bool {
+	inside := isEmptyRow(b[0])
+	pushRow((*[Height][Width]string)(b), row)
+	return inside
+}
+
+// removeRows удаляет из сетки строки rows, заданные сверху вниз, и сдвигает всё, что выше, вниз.
+// Общая для поля и сопутствующих ему сеток, чтобы их строки не расходились
+func removeRows[T any](grid *[Height][Width]T, rows []int) {
+	for _, y := range rows {
+		copy(grid[1:y+1], grid[:y])
+		grid[0] = [Width]T{}
+	}
+}
+
+// pushRow сдвигает сетку на строку вверх, отбрасывая верхнюю строку, и добавляет row снизу
+func pushRow[T any](grid *[Height][Width]T, row [Width]T) {
+	copy(grid[:Height-1], grid[1:])
+	grid[Height-1] = row
+}
+
+// garbageRows возвращает количество строк, в которых остался мусор
+func (b *Board) garbageRows() int {
+	n := 0
+	for _, row := range b {
+		if isGarbageRow(row) {
+			n++
+		}
+	}
+	return n
+}
+
+// isGarbageRow сообщает, есть ли в строке мусорные клетки
+func isGarbageRow(row [Width]string) bool {
+	for _, cell := range row {
+		if cell == GarbageKind {
+			return true
+		}
+	}
+	return false
+}
+
+// isEmptyRow сообщает, пуста ли строка
+func isEmptyRow(row [Width]string) bool {
+	for _, cell := range row {
+		if cell != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// isEmpty сообщает, пусто ли поле
+func (b *Board) isEmpty() bool {
+	for _, row := range b {
+		for _, cell := range row {
+			if cell != "" {
+				return false
+			}
+		}
+	}
+	return true
+}
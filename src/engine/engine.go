@@ -0,0 +1,533 @@
+// Package engine содержит правила игры без привязки к отрисовке и вводу:
+// поле, активную фигуру, очередь, счёт и игровые часы.
+package engine
+
+import (
+	"math"
+	"time"
+)
+
+// Все задержки измеряются в кадрах симуляции, один вызов Step — один кадр
+const (
+	TPS              = 60 // кадров симуляции в секунду
+	lockDelayDefault = 30 // 0,5 с
+	MaxPreviews      = 6
+	gravityUnit      = 1 << 16
+)
+
+// Config задаёт параметры партии
+type Config struct {
+	Level          int     // начальный уровень, от 1
+	LinesPerLevel  int     // уровень растёт каждые LinesPerLevel линий, 0 — не растёт
+	LineGoal       int     // число линий для победы, 0 — без ограничения
+	TimeLimit      int     // длительность партии в кадрах, 0 — без ограничения
+	DigGoal        int     // число мусорных линий для победы, 0 — без мусора
+	GarbageHeight  int     // сколько мусорных строк поддерживать на поле при DigGoal
+	Messiness      float64 // вероятность смены столбца дыры между мусорными строками, от 0 до 1
+	RiseInterval   int     // начальный интервал подъёма мусора снизу в кадрах, 0 — мусор не поднимается
+	Randomizer     RandomizerKind
+	Seed           uint64 // полностью определяет последовательность фигур
+	DisableHold    bool
+	Previews       int      // размер видимой очереди, от 1 до MaxPreviews
+	Handling       Handling // при нулевом SDF используется DefaultHandling
+	LockPolicy     LockPolicy
+	LockDelay      int // задержка фиксации в кадрах, 0 — по умолчанию
+	ARE            int // задержка появления следующей фигуры после фиксации в кадрах
+	LineClearDelay int // дополнительная задержка появления после очистки линий в кадрах
+	Ruleset        Ruleset
+}
+
+// LockResult описывает фиксацию фигуры
+type LockResult struct {
+	Kind         string
+	Lines        int
+	Garbage      int // сколько из очищенных линий были мусорными
+	TSpin        TSpin
+	Points       int
+	Combo        int // номер очистки в серии, начиная с 0; -1 — серии нет
+	BackToBack   bool
+	PerfectClear bool
+}
+
+// Engine представляет одну партию
+type Engine struct {
+	config         Config
+	board          Board
+	lockedAt       [Height][Width]int // кадр, в который была занята каждая клетка поля
+	current        *Piece
+	queue          []string
+	hold           string
+	canHold        bool
+	randomizer     Randomizer
+	score          int
+	scoring        scoring
+	lines          int
+	garbage        garbage
+	garbageCleared int
+	riseTimer      int
+	riseInterval   int
+	entryDelay     int // кадров до появления следующей фигуры, пока идёт ARE
+	inputs         []InputChange
+	pieces         int
+	faults         int
+	pieceInputs    int // нажатия сдвигов и поворотов, потраченные на текущую фигуру
+	level          int
+	frames         int
+	fall           int // накопленный путь падения в долях клетки (1/gravityUnit)
+	lockFrames     int
+	lockResets     int
+	lowestY        int
+	grounded       bool
+	hasMoved       bool // за текущий кадр игрок сдвинул или повернул фигуру
+	lastKick       int  // номер смещения последнего поворота или -1, если после него фигура двигалась
+	lastLock       LockResult
+	listeners      []func(LockResult)
+	held           Input
+	dasDir         Input // направление автоповтора: InputLeft, InputRight или 0
+	dasCharge      int
+	arrTimer       int
+	dasCutUntil    int // последний кадр паузы автоповтора после поворота или появления фигуры
+	isOver         bool
+	isWon          bool
+}
+
+// New создает новую партию с заданными параметрами
+func New(config Config) *Engine {
+	e := &Engine{
+		config:   config,
+		scoring:  newScoring(config.Ruleset),
+		lastLock: LockResult{Combo: -1},
+	}
+	if e.config.Handling.SDF == 0 {
+		e.config.Handling = DefaultHandling
+	}
+	e.randomizer = NewRandomizer(config.Randomizer, newRand(config.Seed))
+	e.garbage = newGarbage(config.Seed)
+	e.riseInterval = config.RiseInterval
+	e.refillGarbage()
+	if e.config.Level < 1 {
+		e.config.Level = 1
+	}
+	e.level = e.config.Level
+	if e.config.Previews < 1 {
+		e.config.Previews = 1
+	}
+	if e.config.Previews > MaxPreviews {
+		e.config.Previews = MaxPreviews
+	}
+	e.spawn(e.randomizer.Next())
+	e.cutDAS()
+	for i := 0; i < MaxPreviews; i++ {
+		e.queue = append(e.queue, e.randomizer.Next())
+	}
+	e.canHold = !config.DisableHold
+	return e
+}
+
+// Step продвигает симуляцию на один кадр с учётом удерживаемых кнопок
+func (e *Engine) Step(in Input) {
+	if e.isOver {
+		return
+	}
+	e.recordInput(in)
+	if e.config.TimeLimit > 0 && e.frames >= e.config.TimeLimit {
+		// Партия на время завершается успешно, если игрок дожил до конца
+		e.isOver = true
+		e.isWon = true
+		return
+	}
+	pressed := in &^ e.held
+	e.held = in
+	e.frames++
+	e.updateRise()
+	if e.isOver {
+		return
+	}
+	if e.entryDelay > 0 {
+		// Во время ARE фигуры нет, но DAS продолжает заряжаться
+		e.autoShift(in, pressed)
+		e.entryDelay--
+		if e.entryDelay == 0 {
+			e.spawnNext(in)
+		}
+		return
+	}
+	for _, b := range []Input{InputLeft, InputRight, InputRotateCW, InputRotateCCW} {
+		if pressed.Has(b) {
+			e.pieceInputs++
+		}
+	}
+	e.hasMoved = false
+
+	e.autoShift(in, pressed)
+
+	if pressed.Has(InputHold) && e.canHold {
+		e.holdPiece()
+		if e.isOver {
+			return
+		}
+	}
+
+	if pressed.Has(InputRotateCCW) && e.rotate(-1) >= 0 {
+		e.cutDAS()
+	}
+	if pressed.Has(InputRotateCW) && e.rotate(1) >= 0 {
+		e.cutDAS()
+	}
+
+	if pressed.Has(InputHardDrop) {
+		cells := 0
+		for e.move(0, 1) {
+			cells++
+		}
+		e.score += e.scoring.drop(cells, true)
+		e.lockPiece()
+		return
+	}
+
+	isSoftDrop := in.Has(InputSoftDrop)
+	softCells := 0
+	gravity := gravityToUnits(Gravity(e.level))
+	if isSoftDrop {
+		if e.config.Handling.SDF >= SoftDropInfinite {
+			for e.move(0, 1) {
+				softCells++
+			}
+		}
+		gravity *= e.config.Handling.SDF
+	}
+	e.fall += gravity
+	for e.fall >= gravityUnit {
+		e.fall -= gravityUnit
+		if !e.move(0, 1) {
+			e.fall = 0
+			break
+		}
+		if isSoftDrop {
+			softCells++
+		}
+	}
+	if softCells > 0 {
+		e.score += e.scoring.drop(softCells, false)
+	}
+
+	if e.hasMoved {
+		e.onPlayerMove()
+	}
+	e.updateLock()
+}
+
+// gravityToUnits переводит гравитацию из G в целые доли клетки, чтобы
+// накопление падения не зависело от ошибок округления с плавающей точкой.
+// Округление вверх не даёт фигуре проходить строку медленнее, чем задаёт кривая
+func gravityToUnits(g float64) int {
+	return int(math.Ceil(g * gravityUnit))
+}
+
+// move сдвигает активную фигуру, если это возможно
+func (e *Engine) move(dx, dy int) bool {
+	moved := e.current.moved(dx, dy)
+	if e.board.collides(moved) {
+		return false
+	}
+	e.current = moved
+	e.lastKick = -1
+	return true
+}
+
+// rotate поворачивает активную фигуру на dir четвертей, перебирая смещения SRS.
+// Возвращает номер сработавшего смещения или -1, если поворот невозможен
+func (e *Engine) rotate(dir int) int {
+	from := e.current.Rotation
+	to := from.turned(dir)
+	for i, k := range kicksFor(e.current.Kind, from, to) {
+		rotated := *e.current
+		rotated.Rotation = to
+		rotated.X += k.x
+		rotated.Y -= k.y
+		if !e.board.collides(&rotated) {
+			*e.current = rotated
+			e.hasMoved = true
+			e.lastKick = i
+			return i
+		}
+	}
+	return -1
+}
+
+// lockPiece фиксирует активную фигуру, очищает линии и выпускает следующую
+func (e *Engine) lockPiece() {
+	tspin := e.detectTSpin()
+	e.faults += e.finesseFaults()
+	e.pieces++
+	if !e.board.place(e.current) {
+		e.isOver = true
+		return
+	}
+	e.stampPiece()
+	rows, garbage := e.board.clearLines()
+	removeRows(&e.lockedAt, rows)
+	cleared := len(rows)
+	result := LockResult{
+		Kind:         e.current.Kind,
+		Lines:        cleared,
+		Garbage:      garbage,
+		TSpin:        tspin,
+		PerfectClear: cleared > 0 && e.board.isEmpty(),
+	}
+	result.Points = e.scoring.lock(&result, e.Level())
+	e.score += result.Points
+	e.lines += cleared
+	e.garbageCleared += garbage
+	e.updateLevel()
+	e.lastLock = result
+	for _, listener := range e.listeners {
+		listener(result)
+	}
+	if e.config.LineGoal > 0 && e.lines >= e.config.LineGoal {
+		e.isOver = true
+		e.isWon = true
+		return
+	}
+	if e.config.DigGoal > 0 && e.garbageCleared >= e.config.DigGoal {
+		e.isOver = true
+		e.isWon = true
+		return
+	}
+	e.refillGarbage()
+	if e.isOver {
+		return
+	}
+
+	if !e.config.Handling.PreserveDAS {
+		e.dasCharge = 0
+	}
+	delay := e.config.ARE
+	if cleared > 0 {
+		delay += e.config.LineClearDelay
+	}
+	if delay > 0 {
+		e.entryDelay = delay
+		return
+	}
+	e.spawnNext(0)
+}
+
+// spawnNext выпускает следующую фигуру из очереди. Кнопки, удерживаемые в момент
+// появления, выполняют начальный запас (IHS) и начальный поворот (IRS)
+func (e *Engine) spawnNext(in Input) {
+	e.spawn(e.popQueue())
+	e.canHold = !e.config.DisableHold
+	e.cutDAS()
+	if e.isOver {
+		return
+	}
+	if in.Has(InputHold) && e.canHold {
+		e.holdPiece()
+		if e.isOver {
+			return
+		}
+	}
+	dir := 0
+	if in.Has(InputRotateCW) {
+		dir = 1
+	} else if in.Has(InputRotateCCW) {
+		dir = -1
+	}
+	if dir != 0 && e.rotate(dir) >= 0 {
+		e.pieceInputs++
+	}
+}
+
+// holdPiece откладывает активную фигуру в запас и достаёт оттуда предыдущую.
+// До фиксации следующей фигуры повторно воспользоваться запасом нельзя
+func (e *Engine) holdPiece() {
+	kind := e.hold
+	e.hold = e.current.Kind
+	e.canHold = false
+	if kind == "" {
+		kind = e.popQueue()
+	}
+	e.spawn(kind)
+}
+
+// popQueue забирает первую фигуру из очереди и дополняет очередь из генератора
+func (e *Engine) popQueue() string {
+	kind := e.queue[0]
+	copy(e.queue, e.queue[1:])
+	e.queue[len(e.queue)-1] = e.randomizer.Next()
+	return kind
+}
+
+// spawn выпускает фигуру в точке появления и проверяет, есть ли для неё место
+func (e *Engine) spawn(kind string) {
+	e.current = newPiece(kind)
+	e.grounded = false
+	e.fall = 0
+	e.lockFrames = 0
+	e.lockResets = 0
+	e.lowestY = e.current.Y
+	e.lastKick = -1
+	e.pieceInputs = 0
+	if e.board.collides(e.current) {
+		e.isOver = true
+	}
+}
+
+// stampPiece запоминает кадр фиксации для клеток активной фигуры
+func (e *Engine) stampPiece() {
+	p := e.current
+	for i, row := range p.Shape() {
+		for j, cell := range row {
+			if cell != 0 && p.Y+i >= 0 {
+				e.lockedAt[p.Y+i][p.X+j] = e.frames
+			}
+		}
+	}
+}
+
+// LockedAt возвращает кадр, в который была занята клетка поля
+func (e *Engine) LockedAt(x, y int) int {
+	if x < 0 || x >= Width || y < 0 || y >= Height {
+		return 0
+	}
+	return e.lockedAt[y][x]
+}
+
+// InEntryDelay сообщает, что предыдущая фигура зафиксирована, а следующая ещё не появилась
+func (e *Engine) InEntryDelay() bool {
+	return e.entryDelay > 0
+}
+
+// Board возвращает копию игрового поля
+func (e *Engine) Board() Board {
+	return e.board
+}
+
+// Current возвращает активную фигуру
+func (e *Engine) Current() Piece {
+	return *e.current
+}
+
+// Ghost возвращает активную фигуру в положении, где она окажется после жёсткого сброса
+func (e *Engine) Ghost() Piece {
+	ghost := *e.current
+	for {
+		ghost.Y++
+		if e.board.collides(&ghost) {
+			ghost.Y--
+			return ghost
+		}
+	}
+}
+
+// Queue возвращает видимую часть очереди следующих фигур
+func (e *Engine) Queue() []string {
+	return append([]string(nil), e.queue[:e.config.Previews]...)
+}
+
+// Seed возвращает сид партии
+func (e *Engine) Seed() uint64 {
+	return e.config.Seed
+}
+
+// Hold возвращает тип фигуры в запасе или "", если запас пуст
+func (e *Engine) Hold() string {
+	return e.hold
+}
+
+// CanHold сообщает, можно ли сейчас воспользоваться запасом
+func (e *Engine) CanHold() bool {
+	return e.canHold
+}
+
+// Score возвращает текущий счёт
+func (e *Engine) Score() int {
+	return e.score
+}
+
+// Lines возвращает количество очищенных линий
+func (e *Engine) Lines() int {
+	return e.lines
+}
+
+// GarbageCleared возвращает количество очищенных мусорных линий
+func (e *Engine) GarbageCleared() int {
+	return e.garbageCleared
+}
+
+// Level возвращает номер текущего уровня, на который умножаются очки
+func (e *Engine) Level() int {
+	return e.level
+}
+
+// Combo возвращает номер текущей очистки в серии или -1, если серии нет
+func (e *Engine) Combo() int {
+	return e.lastLock.Combo
+}
+
+// BackToBack возвращает длину серии сложных очисток подряд или -1, если серии нет
+func (e *Engine) BackToBack() int {
+	return e.scoring.b2b
+}
+
+// Pieces возвращает количество зафиксированных фигур
+func (e *Engine) Pieces() int {
+	return e.pieces
+}
+
+// PPS возвращает среднее число фигур в секунду
+func (e *Engine) PPS() float64 {
+	if e.frames == 0 {
+		return 0
+	}
+	return float64(e.pieces) * TPS / float64(e.frames)
+}
+
+// FinesseFaults возвращает общее число лишних нажатий за партию
+func (e *Engine) FinesseFaults() int {
+	return e.faults
+}
+
+// Frames возвращает количество кадров, прошедших с начала партии
+func (e *Engine) Frames() int {
+	return e.frames
+}
+
+// TimeLeft возвращает число кадров до конца партии на время или 0 без ограничения
+func (e *Engine) TimeLeft() int {
+	if e.config.TimeLimit == 0 {
+		return 0
+	}
+	return max(e.config.TimeLimit-e.frames, 0)
+}
+
+// Clock возвращает игровое время партии
+func (e *Engine) Clock() time.Duration {
+	return FramesToDuration(e.frames)
+}
+
+// FramesToDuration переводит количество кадров симуляции во время
+func FramesToDuration(frames int) time.Duration {
+	return time.Duration(frames) * time.Second / TPS
+}
+
+// IsOver сообщает, закончена ли партия
+func (e *Engine) IsOver() bool {
+	return e.isOver
+}
+
+// IsWon сообщает, достигнута ли цель партии: линии, мусор или выдержанное время
+func (e *Engine) IsWon() bool {
+	return e.isWon
+}
+
+// Subscribe добавляет обработчик, который вызывается после каждой фиксации фигуры
+func (e *Engine) Subscribe(listener func(LockResult)) {
+	e.listeners = append(e.listeners, listener)
+}
+
+// LastLock возвращает результат последней фиксации
+func (e *Engine) LastLock() LockResult {
+	return e.lastLock
+}
@@ -0,0 +1,198 @@
+package engine
+
+import "testing"
+
+// setRows заменяет поле схемой нижних строк: "X" — клетка фигуры, "G" — мусор, "." — пусто
+func setRows(e *Engine, rows ...string) {
+	e.board = Board{}
+	top := Height - len(rows)
+	for i, row := range rows {
+		for x, c := range row {
+			switch c {
+			case 'X':
+				e.board[top+i][x] = "j"
+			case 'G':
+				e.board[top+i][x] = GarbageKind
+			}
+		}
+	}
+}
+
+// setCurrent ставит активной фигуру kind в положении rotation с левым верхним углом квадрата в (x, y)
+func setCurrent(e *Engine, kind string, rotation Rotation, x, y int) {
+	e.current = &Piece{Kind: kind, X: x, Y: y, Rotation: rotation}
+	e.lowestY = y
+	e.lockFrames = 0
+	e.lockResets = 0
+	e.lastKick = -1
+}
+
+func TestHoldOncePerPiece(t *testing.T) {
+	e := New(Config{Seed: 1})
+	first, next := e.Current().Kind, e.Queue()[0]
+
+	e.Step(InputHold)
+	if e.Hold() != first || e.Current().Kind != next || e.CanHold() {
+		t.Fatalf("после запаса: запас %q, фигура %q, можно ли снова %v", e.Hold(), e.Current().Kind, e.CanHold())
+	}
+	e.Step(0)
+	e.Step(InputHold)
+	if e.Hold() != first || e.Current().Kind != next {
+		t.Fatalf("повторный запас до фиксации сработал: запас %q, фигура %q", e.Hold(), e.Current().Kind)
+	}
+
+	e.Step(InputHardDrop)
+	if !e.CanHold() {
+		t.Fatal("после фиксации запас недоступен")
+	}
+	e.Step(InputHold)
+	if e.Current().Kind != first || e.Current() != *newPiece(first) {
+		t.Fatalf("из запаса вышла %+v, ожидалась %q в точке появления", e.Current(), first)
+	}
+}
+
+func TestDisableHold(t *testing.T) {
+	e := New(Config{Seed: 1, DisableHold: true})
+	kind := e.Current().Kind
+	e.Step(InputHold)
+	if e.Hold() != "" || e.Current().Kind != kind {
+		t.Fatalf("запас сработал при DisableHold: запас %q, фигура %q", e.Hold(), e.Current().Kind)
+	}
+}
+
+// Часы спринта останавливаются на кадре, в котором очищена последняя линия
+func TestLineGoalStopsClock(t *testing.T) {
+	e := New(Config{Seed: 1, LineGoal: 40})
+	e.lines = 39
+	for i := 0; i < 5; i++ {
+		e.Step(0)
+	}
+	setRows(e, "XXXXXX....")
+	setCurrent(e, "i", Rotation0, 6, Height-2)
+	e.Step(InputHardDrop)
+	if !e.IsOver() || !e.IsWon() || e.Lines() != 40 {
+		t.Fatalf("после 40-й линии: партия окончена %v, выиграна %v, линий %d", e.IsOver(), e.IsWon(), e.Lines())
+	}
+	for i := 0; i < 10; i++ {
+		e.Step(0)
+	}
+	if e.Frames() != 6 {
+		t.Errorf("время партии %d кадров, ожидалось 6", e.Frames())
+	}
+}
+
+// dropAndWait сбрасывает активную фигуру и возвращает номер кадра (с 1), на котором
+// появилась следующая. Кнопки spawnInput удерживаются на кадре появления
+func dropAndWait(e *Engine, spawnInput Input) int {
+	e.Step(InputHardDrop)
+	frame := 1
+	for e.InEntryDelay() && frame < 1000 {
+		in := Input(0)
+		if e.entryDelay == 1 {
+			in = spawnInput
+		}
+		e.Step(in)
+		frame++
+	}
+	return frame
+}
+
+func TestEntryDelay(t *testing.T) {
+	tests := []struct {
+		name           string
+		are, lineClear int
+		clearsLine     bool
+		want           int
+	}{
+		{"без задержек", 0, 0, false, 1},
+		{"ARE", 30, 0, false, 31},
+		{"без очистки задержка очистки не действует", 30, 41, false, 31},
+		{"ARE и очистка линии", 30, 41, true, 72},
+		{"только задержка очистки", 0, 41, true, 42},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			e := New(Config{Seed: 1, ARE: tc.are, LineClearDelay: tc.lineClear})
+			if tc.clearsLine {
+				setRows(e, "XXXXXX....")
+			}
+			setCurrent(e, "i", Rotation0, 6, 0)
+			next := e.Queue()[0]
+			if got := dropAndWait(e, 0); got != tc.want {
+				t.Errorf("следующая фигура появилась на кадре %d, ожидалось %d", got, tc.want)
+			}
+			if p := e.Current(); p != *newPiece(next) {
+				t.Errorf("появилась %+v, ожидалась %q в точке появления", p, next)
+			}
+		})
+	}
+}
+
+func TestInitialRotationAndHold(t *testing.T) {
+	tests := []struct {
+		name        string
+		disableHold bool
+		in          Input
+		rotation    Rotation
+		isHeld      bool // фигура из очереди ушла в запас и вместо неё вышла следующая
+	}{
+		{"IRS по часовой", false, InputRotateCW, RotationR, false},
+		{"IRS против часовой", false, InputRotateCCW, RotationL, false},
+		{"IHS", false, InputHold, Rotation0, true},
+		{"IHS и IRS вместе", false, InputHold | InputRotateCW, RotationR, true},
+		{"IHS при отключённом запасе", true, InputHold, Rotation0, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			e := New(Config{Seed: 1, ARE: 30, DisableHold: tc.disableHold, Previews: 2})
+			setCurrent(e, "t", Rotation0, 3, 0)
+			queue := e.Queue()
+			dropAndWait(e, tc.in)
+			kind, hold := queue[0], ""
+			if tc.isHeld {
+				kind, hold = queue[1], queue[0]
+			}
+			if p := e.Current(); p.Kind != kind || p.Rotation != tc.rotation || e.Hold() != hold {
+				t.Errorf("фигура %q в положении %d, запас %q; ожидалось %q, %d и %q",
+					p.Kind, p.Rotation, e.Hold(), kind, tc.rotation, hold)
+			}
+		})
+	}
+}
+
+// После IHS запас заблокирован до фиксации, как после обычного запаса
+func TestInitialHoldLocksHold(t *testing.T) {
+	e := New(Config{Seed: 1, ARE: 30})
+	setCurrent(e, "t", Rotation0, 3, 0)
+	dropAndWait(e, InputHold)
+	kind, hold := e.Current().Kind, e.Hold()
+	if e.CanHold() {
+		t.Fatal("после IHS запас снова доступен")
+	}
+	e.Step(0)
+	e.Step(InputHold)
+	if e.Current().Kind != kind || e.Hold() != hold {
+		t.Errorf("повторный запас после IHS сработал: фигура %q, запас %q", e.Current().Kind, e.Hold())
+	}
+}
+
+// Кадры фиксации клеток сдвигаются вместе со строками поля при очистке линий
+func TestLockedAtFollowsClears(t *testing.T) {
+	e := New(Config{Seed: 1})
+	setRows(e, "X.........", "XXXXXX....")
+	e.lockedAt[Height-2][0] = 5
+	e.frames = 9
+	setCurrent(e, "i", Rotation0, 6, Height-2)
+	e.lockPiece()
+	if e.LastLock().Lines != 1 {
+		t.Fatalf("очищено %d линий, ожидалась одна", e.LastLock().Lines)
+	}
+	if got := e.LockedAt(0, Height-1); got != 5 {
+		t.Errorf("клетка над очищенной строкой помечена кадром %d, ожидалось 5", got)
+	}
+	for x := 0; x < Width; x++ {
+		if got := e.LockedAt(x, Height-2); got != 0 {
+			t.Errorf("в опустевшей строке клетка %d помечена кадром %d", x, got)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package engine
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// finesseState — положение фигуры на пустом поле без учёта высоты
+type finesseState struct {
+	x        int
+	rotation Rotation
+}
+
+// finesseMoves — действия, из которых строится оптимальная расстановка:
+// одиночные сдвиги, сдвиги до стены с DAS и повороты
+var finesseMoves = []func(b *Board, p *Piece) bool{
+	func(b *Board, p *Piece) bool { return tryMove(b, p, -1) },
+	func(b *Board, p *Piece) bool { return tryMove(b, p, 1) },
+	func(b *Board, p *Piece) bool { return tryDAS(b, p, -1) },
+	func(b *Board, p *Piece) bool { return tryDAS(b, p, 1) },
+	func(b *Board, p *Piece) bool { return tryRotate(b, p, 1) },
+	func(b *Board, p *Piece) bool { return tryRotate(b, p, -1) },
+}
+
+// finesseFaults возвращает число лишних нажатий, которые игрок потратил на фигуру,
+// по сравнению с кратчайшим способом поставить её так же на пустом поле.
+// Фигуры, поставленные подкруткой или под навес, не оцениваются
+func (e *Engine) finesseFaults() int {
+	final := *e.current
+	dropped := *newPiece(final.Kind)
+	dropped.X, dropped.Rotation = final.X, final.Rotation
+	if e.board.collides(&dropped) {
+		return 0
+	}
+	for !e.board.collides(dropped.moved(0, 1)) {
+		dropped.Y++
+	}
+	if dropped.Y != final.Y {
+		return 0
+	}
+
+	optimal := minimalInputs(final.Kind, footprint(&final))
+	if optimal < 0 || e.pieceInputs <= optimal {
+		return 0
+	}
+	return e.pieceInputs - optimal
+}
+
+// minimalInputs ищет в ширину наименьшее число нажатий, за которое фигура
+// из точки появления принимает положение с данным отпечатком
+func minimalInputs(kind string, target string) int {
+	var empty Board
+	start := *newPiece(kind)
+	depth := map[finesseState]int{{start.X, start.Rotation}: 0}
+	queue := []Piece{start}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		d := depth[finesseState{p.X, p.Rotation}]
+		if footprint(&p) == target {
+			return d
+		}
+		for _, move := range finesseMoves {
+			next := p
+			if !move(&empty, &next) {
+				continue
+			}
+			state := finesseState{next.X, next.Rotation}
+			if _, seen := depth[state]; seen {
+				continue
+			}
+			depth[state] = d + 1
+			queue = append(queue, next)
+		}
+	}
+	return -1
+}
+
+// footprint описывает форму и столбцы фигуры без учёта высоты, чтобы
+// совпадающие положения S, Z, I и O считались одинаковыми
+func footprint(p *Piece) string {
+	var cells []string
+	minY := Height
+	for i, row := range p.Shape() {
+		for _, cell := range row {
+			if cell != 0 {
+				minY = min(minY, i)
+			}
+		}
+	}
+	for i, row := range p.Shape() {
+		for j, cell := range row {
+			if cell != 0 {
+				cells = append(cells, fmt.Sprintf("%d:%d", p.X+j, i-minY))
+			}
+		}
+	}
+	slices.Sort(cells)
+	return strings.Join(cells, ",")
+}
+
+// tryMove сдвигает фигуру на одну клетку, если это возможно
+func tryMove(b *Board, p *Piece, dx int) bool {
+	if b.collides(p.moved(dx, 0)) {
+		return false
+	}
+	p.X += dx
+	return true
+}
+
+// tryDAS сдвигает фигуру до упора
+func tryDAS(b *Board, p *Piece, dx int) bool {
+	moved := false
+	for tryMove(b, p, dx) {
+		moved = true
+	}
+	return moved
+}
+
+// tryRotate поворачивает фигуру с учётом смещений SRS
+func tryRotate(b *Board, p *Piece, dir int) bool {
+	to := p.Rotation.turned(dir)
+	for _, k := range kicksFor(p.Kind, p.Rotation, to) {
+		rotated := *p
+		rotated.Rotation = to
+		rotated.X += k.x
+		rotated.Y -= k.y
+		if !b.collides(&rotated) {
+			*p = rotated
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package engine
+
+import "testing"
+
+func TestMinimalInputs(t *testing.T) {
+	tests := []struct {
+		name     string
+		kind     string
+		rotation Rotation
+		x        int
+		want     int
+	}{
+		{"T на месте появления", "t", Rotation0, 3, 0},
+		{"T на шаг влево", "t", Rotation0, 2, 1},
+		{"T к левой стене", "t", Rotation0, 0, 1},
+		{"T к стене и шаг обратно", "t", Rotation0, 1, 2},
+		{"T повёрнута", "t", RotationR, 3, 1},
+		{"T перевёрнута", "t", Rotation2, 3, 2},
+		{"O к правой стене", "o", Rotation0, 8, 1},
+		{"O на два шага влево", "o", Rotation0, 2, 2},
+		{"I горизонтально", "i", Rotation0, 3, 0},
+		{"I вертикально у левой стены", "i", RotationR, -2, 2},
+		// Вертикальные положения S совпадают, годится любой поворот
+		{"S вертикально", "s", RotationL, 3, 1},
+		{"S вертикально через другой поворот", "s", RotationR, 2, 1},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p := Piece{Kind: tc.kind, X: tc.x, Rotation: tc.rotation}
+			if got := minimalInputs(tc.kind, footprint(&p)); got != tc.want {
+				t.Errorf("minimalInputs = %d, ожидалось %d", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestFinesseFaults(t *testing.T) {
+	tests := []struct {
+		name   string
+		inputs []Input
+		want   int
+	}{
+		{"без лишних нажатий", []Input{InputLeft, 0}, 0},
+		{"влево, вправо, влево", []Input{InputLeft, 0, InputRight, 0, InputLeft, 0}, 2},
+		{"четыре поворота", []Input{InputRotateCW, 0, InputRotateCW, 0, InputRotateCW, 0, InputRotateCW, 0}, 4},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			e := New(Config{Seed: 1})
+			setCurrent(e, "t", Rotation0, 3, 0)
+			for _, in := range tc.inputs {
+				e.Step(in)
+			}
+			e.Step(InputHardDrop)
+			if got := e.FinesseFaults(); got != tc.want {
+				t.Errorf("ошибок техники %d, ожидалось %d", got, tc.want)
+			}
+		})
+	}
+}
+
+// Фигура, заведённая под навес, не оценивается: на пустом поле так её не поставить
+func TestFinesseSkipsTucks(t *testing.T) {
+	e := New(Config{Seed: 1})
+	setRows(e, "XXXXXXX...", "..........", "..........")
+	setCurrent(e, "o", Rotation0, 0, Height-2)
+	if e.board.collides(e.current) {
+		t.Fatal("фигура пересекается с полем")
+	}
+	e.pieceInputs = 10
+	if got := e.finesseFaults(); got != 0 {
+		t.Errorf("ошибок техники %d, ожидалось 0", got)
+	}
+}
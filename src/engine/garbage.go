@@ -0,0 +1,95 @@
+package engine
+
+import "math/rand"
+
+// GarbageKind — содержимое клеток мусорных строк
+const GarbageKind = "garbage"
+
+// garbageSeedSalt отделяет генератор мусора от генератора фигур, чтобы
+// мусор не менял последовательность фигур при том же сиде
+const garbageSeedSalt = 0x6a09e667f3bcc909
+
+// Мусор в режиме выживания поднимается всё чаще: каждый раз интервал
+// сокращается на 1/riseSpeedup, но не становится меньше riseMinInterval
+const (
+	riseSpeedup     = 20
+	riseMinInterval = 30 // 0,5 с
+)
+
+// garbage хранит состояние генерации мусорных строк
+type garbage struct {
+	rand *rand.Rand
+	hole int // столбец дыры в последней созданной строке, -1 — строк ещё не было
+}
+
+// newGarbage создает генератор мусора для партии с данным сидом
+func newGarbage(seed uint64) garbage {
+	return garbage{rand: newRand(seed ^ garbageSeedSalt), hole: -1}
+}
+
+// row создает мусорную строку с одной дырой. С вероятностью messiness дыра
+// переходит в другой столбец, иначе остаётся под дырой предыдущей строки
+func (g *garbage) row(messiness float64) [Width]string {
+	if g.hole < 0 {
+		g.hole = g.rand.Intn(Width)
+	} else if g.rand.Float64() < messiness {
+		g.hole = (g.hole + 1 + g.rand.Intn(Width-1)) % Width
+	}
+	var row [Width]string
+	for x := range row {
+		if x != g.hole {
+			row[x] = GarbageKind
+		}
+	}
+	return row
+}
+
+// refillGarbage добавляет снизу мусорные строки, пока их на поле не станет
+// GarbageHeight или сколько осталось очистить до DigGoal
+func (e *Engine) refillGarbage() {
+	if e.config.DigGoal == 0 {
+		return
+	}
+	target := min(e.config.GarbageHeight, e.config.DigGoal-e.garbageCleared)
+	for n := e.board.garbageRows(); n < target; n++ {
+		if !e.pushGarbage() {
+			return
+		}
+	}
+}
+
+// updateRise отсчитывает время до подъёма мусора и выталкивает строку снизу.
+// Активная фигура поднимается вместе со стеком, если иначе пересеклась бы с ним
+func (e *Engine) updateRise() {
+	if e.config.RiseInterval == 0 {
+		return
+	}
+	e.riseTimer++
+	if e.riseTimer < e.riseInterval {
+		return
+	}
+	e.riseTimer = 0
+	e.riseInterval = max(e.riseInterval-e.riseInterval/riseSpeedup, riseMinInterval)
+	if !e.pushGarbage() {
+		return
+	}
+	if e.entryDelay == 0 && e.board.collides(e.current) {
+		e.current.Y--
+		e.lowestY--
+	}
+}
+
+// pushGarbage выталкивает снизу новую мусорную строку. Возвращает false и
+// завершает партию, если стек вытолкнут за верх поля
+func (e *Engine) pushGarbage() bool {
+	var stamps [Width]int
+	for x := range stamps {
+		stamps[x] = e.frames
+	}
+	pushRow(&e.lockedAt, stamps)
+	if !e.board.pushRow(e.garbage.row(e.config.Messiness)) {
+		e.isOver = true
+		return false
+	}
+	return true
+}
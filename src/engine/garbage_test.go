@@ -0,0 +1,153 @@
+package engine
+
+import (
+	"slices"
+	"testing"
+)
+
+// holeOf возвращает столбец единственной дыры мусорной строки или -1
+func holeOf(row [Width]string) int {
+	hole := -1
+	for x, cell := range row {
+		switch {
+		case cell == "" && hole < 0:
+			hole = x
+		case cell == "":
+			return -1
+		case cell != GarbageKind:
+			return -1
+		}
+	}
+	return hole
+}
+
+func TestGarbageRows(t *testing.T) {
+	tests := []struct {
+		messiness float64
+		wantMoves int // сколько раз из 99 дыра должна сменить столбец, -1 — не проверять
+	}{
+		{0, 0},
+		{1, 99},
+		{0.5, -1},
+	}
+	for _, tc := range tests {
+		g := newGarbage(7)
+		prev, moves := -1, 0
+		for i := 0; i < 100; i++ {
+			hole := holeOf(g.row(tc.messiness))
+			if hole < 0 {
+				t.Fatalf("messiness %.1f, строка %d: ожидалась ровно одна дыра", tc.messiness, i)
+			}
+			if prev >= 0 && hole != prev {
+				moves++
+			}
+			prev = hole
+		}
+		if tc.wantMoves >= 0 && moves != tc.wantMoves {
+			t.Errorf("messiness %.1f: дыра сменилась %d раз, ожидалось %d", tc.messiness, moves, tc.wantMoves)
+		}
+	}
+}
+
+// Мусор зависит только от сида и не сдвигает последовательность фигур
+func TestGarbageDeterministic(t *testing.T) {
+	config := Config{Seed: 99, DigGoal: 100, GarbageHeight: 10, Messiness: 0.3, Previews: MaxPreviews}
+	a, b := New(config), New(config)
+	if a.Board() != b.Board() {
+		t.Fatal("один и тот же сид дал разный мусор")
+	}
+	plain := New(Config{Seed: 99, Previews: MaxPreviews})
+	if a.Current().Kind != plain.Current().Kind || !slices.Equal(a.Queue(), plain.Queue()) {
+		t.Errorf("мусор изменил очередь: %v против %v", a.Queue(), plain.Queue())
+	}
+}
+
+func TestDigRefill(t *testing.T) {
+	tests := []struct {
+		height, goal, want int
+	}{
+		{10, 100, 10},
+		{10, 5, 5},
+		{0, 5, 0},
+		{10, 0, 0},
+	}
+	for _, tc := range tests {
+		e := New(Config{Seed: 3, GarbageHeight: tc.height, DigGoal: tc.goal})
+		if got := e.board.garbageRows(); got != tc.want {
+			t.Errorf("высота %d, цель %d: %d мусорных строк, ожидалось %d", tc.height, tc.goal, got, tc.want)
+		}
+	}
+}
+
+func TestDigGoal(t *testing.T) {
+	e := New(Config{Seed: 5, GarbageHeight: 1, DigGoal: 1})
+	hole := holeOf(e.board[Height-1])
+	if hole < 0 {
+		t.Fatal("на дне нет мусорной строки с дырой")
+	}
+	// Вертикальная I занимает третий столбец своего квадрата
+	setCurrent(e, "i", RotationR, hole-2, Height-4)
+	e.lockPiece()
+	r := e.LastLock()
+	if r.Lines != 1 || r.Garbage != 1 || e.GarbageCleared() != 1 {
+		t.Fatalf("фиксация %+v, очищено мусора %d; ожидалась одна мусорная линия", r, e.GarbageCleared())
+	}
+	if !e.IsOver() || !e.IsWon() {
+		t.Error("цель по мусору достигнута, но партия не выиграна")
+	}
+}
+
+func TestClearGarbageLines(t *testing.T) {
+	e := New(Config{Seed: 1})
+	setRows(e, "XXXXXXXXXX", "GGGGGGGGGG", "GGGG.GGGGG")
+	rows, garbage := e.board.clearLines()
+	if !slices.Equal(rows, []int{Height - 3, Height - 2}) || garbage != 1 || e.board.garbageRows() != 1 {
+		t.Errorf("очищены строки %v, из них мусорных %d, осталось мусорных строк %d; ожидалось %d и %d, 1 и 1",
+			rows, garbage, e.board.garbageRows(), Height-3, Height-2)
+	}
+}
+
+func TestRise(t *testing.T) {
+	e := New(Config{Seed: 1, RiseInterval: 60})
+	setCurrent(e, "t", Rotation0, 3, Height-2)
+	for i := 1; i < 60; i++ {
+		e.updateRise()
+	}
+	if e.board.garbageRows() != 0 {
+		t.Fatal("мусор поднялся раньше интервала")
+	}
+	e.frames = 42
+	e.updateRise()
+	if e.board.garbageRows() != 1 || holeOf(e.board[Height-1]) < 0 {
+		t.Fatal("после интервала снизу не появилась мусорная строка")
+	}
+	if got := e.LockedAt(0, Height-1); got != 42 {
+		t.Errorf("время появления мусора %d, ожидалось 42", got)
+	}
+	if e.riseInterval != 57 {
+		t.Errorf("следующий интервал %d, ожидалось 57", e.riseInterval)
+	}
+	if p := e.Current(); p.Y != Height-3 || e.board.collides(e.current) {
+		t.Errorf("фигура на опоре в строке %d, ожидалось вытолкнуть её в %d", p.Y, Height-3)
+	}
+}
+
+func TestRiseMinInterval(t *testing.T) {
+	e := New(Config{Seed: 1, RiseInterval: riseMinInterval + 1})
+	for i := 0; i <= riseMinInterval; i++ {
+		e.updateRise()
+	}
+	if e.riseInterval != riseMinInterval {
+		t.Errorf("интервал %d, ожидалось не меньше %d", e.riseInterval, riseMinInterval)
+	}
+}
+
+// Мусор, вытолкнувший стек за верх поля, завершает партию поражением
+func TestRiseTopOut(t *testing.T) {
+	e := New(Config{Seed: 1, RiseInterval: 1})
+	e.board[0][4] = "j"
+	e.updateRise()
+	if !e.IsOver() || e.IsWon() {
+		t.Error("партия не завершилась поражением")
+	}
+}
@@ -0,0 +1,24 @@
+package engine
+
+// gradeThresholds — минимальный счёт для званий TGM от 9 до S9
+var gradeThresholds = []struct {
+	score int
+	name  string
+}{
+	{0, "9"}, {400, "8"}, {800, "7"}, {1400, "6"}, {2000, "5"}, {3500, "4"},
+	{5500, "3"}, {8000, "2"}, {12000, "1"}, {16000, "S1"}, {22000, "S2"},
+	{30000, "S3"}, {40000, "S4"}, {52000, "S5"}, {66000, "S6"}, {82000, "S7"},
+	{100000, "S8"}, {120000, "S9"},
+}
+
+// Grade возвращает звание по таблице TGM для набранного счёта.
+// Звание имеет смысл при правилах RulesetTGM
+func (e *Engine) Grade() string {
+	grade := gradeThresholds[0].name
+	for _, t := range gradeThresholds {
+		if e.score >= t.score {
+			grade = t.name
+		}
+	}
+	return grade
+}
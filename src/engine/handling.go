@@ -0,0 +1,97 @@
+package engine
+
+// SoftDropInfinite — множитель мягкого сброса, при котором фигура сразу опускается до упора
+const SoftDropInfinite = 41
+
+// Handling описывает настройки управления игрока. Все задержки — в кадрах
+type Handling struct {
+	DAS         int  // задержка перед автоповтором сдвига
+	ARR         int  // интервал автоповтора, 0 — сразу до стены
+	SDF         int  // множитель гравитации при мягком сбросе, SoftDropInfinite — мгновенно
+	DCD         int  // пауза автоповтора после поворота или появления новой фигуры
+	PreserveDAS bool // заряд DAS сохраняется при фиксации и появлении фигуры
+}
+
+// DefaultHandling соответствует прежнему поведению игры: 150 мс до повтора и 50 мс между повторами
+var DefaultHandling = Handling{
+	DAS:         9,
+	ARR:         3,
+	SDF:         6,
+	DCD:         0,
+	PreserveDAS: true,
+}
+
+// autoShift обрабатывает сдвиги влево и вправо с учётом DAS и ARR.
+// При одновременном удержании обеих кнопок действует нажатая последней
+func (e *Engine) autoShift(in, pressed Input) {
+	h := e.config.Handling
+	for _, b := range []Input{InputLeft, InputRight} {
+		if pressed.Has(b) {
+			e.dasDir = b
+			e.dasCharge = 0
+			e.arrTimer = h.ARR
+			e.shift(b)
+		}
+	}
+	if pressed.Has(e.dasDir) {
+		return
+	}
+	if !in.Has(e.dasDir) {
+		e.dasDir = 0
+		e.dasCharge = 0
+		e.arrTimer = h.ARR
+		for _, b := range []Input{InputLeft, InputRight} {
+			if in.Has(b) {
+				e.dasDir = b
+			}
+		}
+		if e.dasDir == 0 {
+			return
+		}
+	}
+
+	if e.dasCharge < h.DAS {
+		e.dasCharge++
+		// Первый повтор происходит сразу, как только DAS заряжен
+		e.arrTimer = h.ARR
+	}
+	// Пауза отсчитывается по кадрам партии, а не по кадрам удержания кнопки
+	if e.frames <= e.dasCutUntil {
+		return
+	}
+	if e.dasCharge < h.DAS {
+		return
+	}
+	if h.ARR == 0 {
+		for e.shift(e.dasDir) {
+		}
+		return
+	}
+	e.arrTimer++
+	if e.arrTimer >= h.ARR {
+		e.arrTimer = 0
+		e.shift(e.dasDir)
+	}
+}
+
+// cutDAS приостанавливает автоповтор на DCD кадров после поворота или появления фигуры
+func (e *Engine) cutDAS() {
+	e.dasCutUntil = e.frames + e.config.Handling.DCD
+}
+
+// shift сдвигает фигуру в сторону, соответствующую кнопке. Во время ARE
+// фигуры нет, и сдвиг только заряжает DAS
+func (e *Engine) shift(b Input) bool {
+	if e.entryDelay > 0 {
+		return false
+	}
+	dx := 1
+	if b == InputLeft {
+		dx = -1
+	}
+	if !e.move(dx, 0) {
+		return false
+	}
+	e.hasMoved = true
+	return true
+}
@@ -0,0 +1,126 @@
+package engine
+
+import (
+	"slices"
+	"testing"
+)
+
+// repeatInput возвращает n кадров с одними и теми же кнопками
+func repeatInput(in Input, n int) []Input {
+	return slices.Repeat([]Input{in}, n)
+}
+
+// shiftFrames подаёт кадры inputs и возвращает номера кадров (с 1), на которых
+// активная фигура сдвинулась по горизонтали
+func shiftFrames(e *Engine, inputs []Input) []int {
+	var frames []int
+	for i, in := range inputs {
+		x := e.Current().X
+		e.Step(in)
+		if e.Current().X != x {
+			frames = append(frames, i+1)
+		}
+	}
+	return frames
+}
+
+func TestAutoShift(t *testing.T) {
+	tests := []struct {
+		name     string
+		handling Handling
+		inputs   []Input
+		want     []int
+		wantX    int
+	}{
+		{"DAS 3, ARR 1", Handling{DAS: 3, ARR: 1, SDF: 1}, repeatInput(InputLeft, 12),
+			[]int{1, 4, 5, 6, 7, 8, 9}, 0},
+		{"DAS 3, ARR 2", Handling{DAS: 3, ARR: 2, SDF: 1}, repeatInput(InputLeft, 12),
+			[]int{1, 4, 6, 8, 10, 12}, 1},
+		{"ARR 0 сразу до стены", Handling{DAS: 3, ARR: 0, SDF: 1}, repeatInput(InputLeft, 6),
+			[]int{1, 4}, 0},
+		{"по умолчанию", DefaultHandling, repeatInput(InputLeft, 20),
+			[]int{1, 10, 13, 16, 19}, 2},
+		{"отпускание сбрасывает заряд", Handling{DAS: 3, ARR: 1, SDF: 1},
+			slices.Concat(repeatInput(InputLeft, 2), repeatInput(0, 1), repeatInput(InputLeft, 4)),
+			[]int{1, 4, 7}, 4},
+		{"последняя нажатая кнопка главнее", Handling{DAS: 3, ARR: 1, SDF: 1},
+			slices.Concat(repeatInput(InputLeft, 4), repeatInput(InputLeft|InputRight, 4)),
+			[]int{1, 4, 5, 8}, 7},
+		{"DCD после поворота", Handling{DAS: 3, ARR: 1, SDF: 1, DCD: 4},
+			slices.Concat(repeatInput(InputLeft, 4), []Input{InputLeft | InputRotateCW}, repeatInput(InputLeft, 6)),
+			[]int{1, 5, 10, 11}, 3},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			e := New(Config{Seed: 1, Handling: tc.handling})
+			setCurrent(e, "t", Rotation0, 7, 0)
+			got := shiftFrames(e, tc.inputs)
+			if !slices.Equal(got, tc.want) || e.Current().X != tc.wantX {
+				t.Errorf("сдвиги на кадрах %v, фигура в столбце %d; ожидалось %v и %d", got, e.Current().X, tc.want, tc.wantX)
+			}
+		})
+	}
+}
+
+func TestSoftDrop(t *testing.T) {
+	tests := []struct {
+		sdf, frames, want int
+	}{
+		// На первом уровне гравитация 1/60 G
+		{1, 70, 1},
+		{6, 35, 3},
+		{SoftDropInfinite, 1, Height - 2},
+	}
+	for _, tc := range tests {
+		e := New(Config{Seed: 1, Handling: Handling{DAS: 9, ARR: 3, SDF: tc.sdf}})
+		setCurrent(e, "t", Rotation0, 3, 0)
+		for i := 0; i < tc.frames; i++ {
+			e.Step(InputSoftDrop)
+		}
+		if got := e.Current().Y; got != tc.want {
+			t.Errorf("SDF %d: за %d кадров фигура опустилась до строки %d, ожидалось %d", tc.sdf, tc.frames, got, tc.want)
+		}
+	}
+}
+
+// Заряд DAS переносится на следующую фигуру только при PreserveDAS
+func TestPreserveDAS(t *testing.T) {
+	for _, preserve := range []bool{true, false} {
+		e := New(Config{Seed: 1, Handling: Handling{DAS: 3, ARR: 1, SDF: 1, PreserveDAS: preserve}})
+		for i := 0; i < 4; i++ {
+			e.Step(InputLeft)
+		}
+		e.Step(InputLeft | InputHardDrop)
+		got := shiftFrames(e, repeatInput(InputLeft, 3))
+		want := []int{1, 2, 3}
+		if !preserve {
+			want = []int{3}
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("PreserveDAS %v: сдвиги новой фигуры на кадрах %v, ожидалось %v", preserve, got, want)
+		}
+	}
+}
+
+// Пауза DCD идёт и тогда, когда кнопки сдвига не нажаты
+func TestDCD(t *testing.T) {
+	h := Handling{DAS: 3, ARR: 1, SDF: 1, DCD: 10}
+	t.Run("первая фигура", func(t *testing.T) {
+		e := New(Config{Seed: 1, Handling: h})
+		setCurrent(e, "t", Rotation0, 7, 0)
+		if got, want := shiftFrames(e, repeatInput(InputLeft, 12)), []int{1, 11, 12}; !slices.Equal(got, want) {
+			t.Errorf("сдвиги на кадрах %v, ожидалось %v", got, want)
+		}
+	})
+	t.Run("после простоя", func(t *testing.T) {
+		e := New(Config{Seed: 1, Handling: h})
+		e.Step(InputHardDrop)
+		for i := 0; i < 60; i++ {
+			e.Step(0)
+		}
+		setCurrent(e, "t", Rotation0, 7, 0)
+		if got, want := shiftFrames(e, repeatInput(InputLeft, 6)), []int{1, 4, 5, 6}; !slices.Equal(got, want) {
+			t.Errorf("сдвиги на кадрах %v, ожидалось %v", got, want)
+		}
+	})
+}
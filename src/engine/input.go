@@ -0,0 +1,19 @@
+package engine
+
+// Input — набор кнопок, удерживаемых на текущем шаге симуляции
+type Input uint8
+
+const (
+	InputLeft Input = 1 << iota
+	InputRight
+	InputSoftDrop
+	InputHardDrop
+	InputRotateCW
+	InputRotateCCW
+	InputHold
+)
+
+// Has сообщает, удерживается ли кнопка
+func (in Input) Has(b Input) bool {
+	return in&b != 0
+}
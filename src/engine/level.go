@@ -0,0 +1,34 @@
+package engine
+
+// GravityCurve — гравитация в G для уровней начиная с первого, по формуле Guideline
+// (0,8 − (уровень − 1) × 0,007)^(уровень − 1) секунд на строку с ограничением в 20G
+var GravityCurve = []float64{
+	0.01667, 0.02102, 0.02698, 0.03526, 0.04692,
+	0.06361, 0.08787, 0.1237, 0.17753, 0.2598,
+	0.38781, 0.59065, 0.91811, 1.45696, 2.36118,
+	3.9091, 6.61354, 11.43794, MaxGravity, MaxGravity,
+}
+
+const (
+	MaxGravity           = 20 // поле высотой 20 клеток пролетается за один кадр
+	DefaultLinesPerLevel = 10
+)
+
+// Gravity возвращает гравитацию для уровня. Уровни выше таблицы используют её последнее значение
+func Gravity(level int) float64 {
+	if level < 1 {
+		level = 1
+	}
+	if level > len(GravityCurve) {
+		level = len(GravityCurve)
+	}
+	return GravityCurve[level-1]
+}
+
+// updateLevel повышает уровень по числу очищенных линий
+func (e *Engine) updateLevel() {
+	if e.config.LinesPerLevel <= 0 {
+		return
+	}
+	e.level = e.config.Level + e.lines/e.config.LinesPerLevel
+}
@@ -0,0 +1,78 @@
+package engine
+
+import "testing"
+
+func TestGravityCurve(t *testing.T) {
+	tests := []struct {
+		level        int
+		framesPerRow int // через сколько кадров фигура опускается на первую строку
+		firstFrame   int // на сколько строк фигура опускается за первый кадр
+	}{
+		{1, 60, 0},
+		{2, 48, 0},
+		{5, 22, 0},
+		{10, 4, 0},
+		{13, 2, 0},
+		{14, 1, 1},
+		{15, 1, 2},
+		{18, 1, 11},
+		// 20G: фигура сразу оказывается на дне
+		{19, 1, Height - 2},
+		{20, 1, Height - 2},
+		{100, 1, Height - 2},
+	}
+	for _, tc := range tests {
+		e := New(Config{Seed: 1, Level: tc.level})
+		setCurrent(e, "t", Rotation0, 3, 0)
+		e.Step(0)
+		if got := e.Current().Y; got != tc.firstFrame {
+			t.Errorf("уровень %d: за первый кадр %d строк, ожидалось %d", tc.level, got, tc.firstFrame)
+		}
+		frames := 1
+		for e.Current().Y == 0 && frames < 1000 {
+			e.Step(0)
+			frames++
+		}
+		if tc.firstFrame == 0 && frames != tc.framesPerRow {
+			t.Errorf("уровень %d: строка пройдена за %d кадров, ожидалось %d", tc.level, frames, tc.framesPerRow)
+		}
+	}
+}
+
+func TestGravity20G(t *testing.T) {
+	first := -1
+	for level := 1; level <= len(GravityCurve); level++ {
+		if Gravity(level) == MaxGravity {
+			first = level
+			break
+		}
+	}
+	if first != len(GravityCurve)-1 {
+		t.Errorf("20G начинается с уровня %d, ожидалось %d", first, len(GravityCurve)-1)
+	}
+	if Gravity(0) != Gravity(1) || Gravity(len(GravityCurve)+10) != MaxGravity {
+		t.Error("уровни за пределами кривой не ограничиваются её краями")
+	}
+}
+
+func TestLevelUp(t *testing.T) {
+	tests := []struct {
+		start, perLevel, lines, want int
+	}{
+		{1, 10, 9, 1},
+		{1, 10, 10, 2},
+		{1, 10, 25, 3},
+		{5, 10, 25, 7},
+		{1, 0, 100, 1},
+		{3, 0, 100, 3},
+	}
+	for _, tc := range tests {
+		e := New(Config{Seed: 1, Level: tc.start, LinesPerLevel: tc.perLevel})
+		e.lines = tc.lines
+		e.updateLevel()
+		if got := e.Level(); got != tc.want {
+			t.Errorf("начало с %d, %d линий на уровень, %d линий: уровень %d, ожидалось %d",
+				tc.start, tc.perLevel, tc.lines, got, tc.want)
+		}
+	}
+}
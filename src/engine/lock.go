@@ -0,0 +1,82 @@
+package engine
+
+// LockPolicy определяет, какие действия игрока продлевают задержку фиксации
+type LockPolicy int
+
+const (
+	LockMoveReset LockPolicy = iota // сдвиги и повороты сбрасывают таймер, не более moveResetLimit раз
+	LockStepReset                   // таймер сбрасывается, только когда фигура опускается ниже
+	LockInfinity                    // любое движение сбрасывает таймер
+	LockNone                        // таймер не сбрасывается
+)
+
+// LockPolicies перечисляет правила фиксации в порядке показа в меню
+var LockPolicies = []LockPolicy{LockMoveReset, LockStepReset, LockInfinity, LockNone}
+
+const moveResetLimit = 15
+
+// String возвращает название правила
+func (p LockPolicy) String() string {
+	switch p {
+	case LockMoveReset:
+		return "Move reset"
+	case LockStepReset:
+		return "Step reset"
+	case LockInfinity:
+		return "Infinity"
+	case LockNone:
+		return "None"
+	}
+	return "?"
+}
+
+// lockDelay возвращает длительность задержки фиксации в кадрах
+func (e *Engine) lockDelay() int {
+	if e.config.LockDelay > 0 {
+		return e.config.LockDelay
+	}
+	return lockDelayDefault
+}
+
+// onPlayerMove продлевает задержку фиксации после сдвига или поворота.
+// Сбросы считаются только после того, как фигура коснулась опоры
+func (e *Engine) onPlayerMove() {
+	if e.lockFrames == 0 {
+		return
+	}
+	switch e.config.LockPolicy {
+	case LockInfinity:
+		e.lockFrames = 0
+	case LockMoveReset:
+		if e.lockResets < moveResetLimit {
+			e.lockFrames = 0
+			e.lockResets++
+		}
+	}
+}
+
+// onDescend обрабатывает опускание фигуры на новую, ещё не достигнутую строку
+func (e *Engine) onDescend() {
+	if e.config.LockPolicy == LockNone {
+		return
+	}
+	e.lockFrames = 0
+	e.lockResets = 0
+}
+
+// updateLock отсчитывает задержку фиксации, пока фигура лежит на опоре,
+// и фиксирует её, когда задержка истекла
+func (e *Engine) updateLock() {
+	if e.current.Y > e.lowestY {
+		e.lowestY = e.current.Y
+		e.onDescend()
+	}
+	e.grounded = e.board.collides(e.current.moved(0, 1))
+	if !e.grounded {
+		return
+	}
+	e.lockFrames++
+	if e.lockFrames >= e.lockDelay() {
+		e.lockPiece()
+	}
+}
@@ -0,0 +1,92 @@
+package engine
+
+import "testing"
+
+// framesToLock кладёт T на пустой пол и постукивает влево и вправо через кадр.
+// Возвращает номер кадра, на котором фигура зафиксировалась, или -1
+func framesToLock(policy LockPolicy, limit int) int {
+	e := New(Config{Seed: 1, LockPolicy: policy})
+	setCurrent(e, "t", Rotation0, 3, Height-2)
+	taps := []Input{InputLeft, 0, InputRight, 0}
+	for frame := 1; frame <= limit; frame++ {
+		e.Step(taps[frame%len(taps)])
+		if e.board != (Board{}) {
+			return frame
+		}
+	}
+	return -1
+}
+
+func TestLockPolicies(t *testing.T) {
+	tests := []struct {
+		policy LockPolicy
+		want   int
+	}{
+		// Сдвиги не продлевают задержку: фиксация через lockDelayDefault кадров
+		{LockNone, lockDelayDefault},
+		{LockStepReset, lockDelayDefault},
+		// Сброс на кадрах 2, 4, …, 30 — ровно moveResetLimit раз, затем полная задержка
+		{LockMoveReset, 2*moveResetLimit + lockDelayDefault - 1},
+		// Пока игрок двигает фигуру, она не фиксируется
+		{LockInfinity, -1},
+	}
+	for _, tc := range tests {
+		t.Run(tc.policy.String(), func(t *testing.T) {
+			if got := framesToLock(tc.policy, 600); got != tc.want {
+				t.Errorf("фиксация на кадре %d, ожидался %d", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestLockResets(t *testing.T) {
+	tests := []struct {
+		policy              LockPolicy
+		afterMove           int // lockFrames после сдвига на опоре при lockFrames = 10
+		afterDescend        int // lockFrames после опускания на новую строку
+		afterMoveOverLimits int // lockFrames после сдвига, когда сбросы исчерпаны
+	}{
+		{LockMoveReset, 0, 0, 10},
+		{LockStepReset, 10, 0, 10},
+		{LockInfinity, 0, 0, 0},
+		{LockNone, 10, 10, 10},
+	}
+	for _, tc := range tests {
+		t.Run(tc.policy.String(), func(t *testing.T) {
+			e := New(Config{Seed: 1, LockPolicy: tc.policy})
+
+			e.lockFrames = 10
+			e.onPlayerMove()
+			if e.lockFrames != tc.afterMove {
+				t.Errorf("после сдвига lockFrames = %d, ожидалось %d", e.lockFrames, tc.afterMove)
+			}
+
+			e.lockFrames = 10
+			e.onDescend()
+			if e.lockFrames != tc.afterDescend {
+				t.Errorf("после опускания lockFrames = %d, ожидалось %d", e.lockFrames, tc.afterDescend)
+			}
+
+			e.lockFrames = 10
+			e.lockResets = moveResetLimit
+			e.onPlayerMove()
+			if e.lockFrames != tc.afterMoveOverLimits {
+				t.Errorf("после исчерпания сбросов lockFrames = %d, ожидалось %d", e.lockFrames, tc.afterMoveOverLimits)
+			}
+		})
+	}
+}
+
+func TestLockDelayConfig(t *testing.T) {
+	e := New(Config{Seed: 1, LockDelay: 5})
+	setCurrent(e, "o", Rotation0, 4, Height-2)
+	for frame := 1; frame <= 5; frame++ {
+		if e.board != (Board{}) {
+			t.Fatalf("фигура зафиксировалась раньше задержки, на кадре %d", frame-1)
+		}
+		e.Step(0)
+	}
+	if e.board == (Board{}) {
+		t.Fatal("фигура не зафиксировалась после задержки в 5 кадров")
+	}
+}
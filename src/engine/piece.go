@@ -0,0 +1,93 @@
+package engine
+
+// Piece представляет фигуру на поле
+type Piece struct {
+	Kind     string
+	X, Y     int
+	Rotation Rotation
+}
+
+// spawnShapes задаёт фигуры в положении появления внутри их ограничивающего квадрата.
+// Остальные положения получаются поворотом квадрата, как того требует SRS
+var spawnShapes = map[string][][]int{
+	"i": {
+		{0, 0, 0, 0},
+		{1, 1, 1, 1},
+		{0, 0, 0, 0},
+		{0, 0, 0, 0},
+	},
+	"j": {
+		{1, 0, 0},
+		{1, 1, 1},
+		{0, 0, 0},
+	},
+	"l": {
+		{0, 0, 1},
+		{1, 1, 1},
+		{0, 0, 0},
+	},
+	"o": {
+		{1, 1},
+		{1, 1},
+	},
+	"s": {
+		{0, 1, 1},
+		{1, 1, 0},
+		{0, 0, 0},
+	},
+	"t": {
+		{0, 1, 0},
+		{1, 1, 1},
+		{0, 0, 0},
+	},
+	"z": {
+		{1, 1, 0},
+		{0, 1, 1},
+		{0, 0, 0},
+	},
+}
+
+// shapes хранит все четыре положения каждой фигуры
+var shapes = map[string][4][][]int{}
+
+func init() {
+	for kind, shape := range spawnShapes {
+		var states [4][][]int
+		states[Rotation0] = shape
+		for r := RotationR; r <= RotationL; r++ {
+			states[r] = rotatedClockwise(states[r-1])
+		}
+		shapes[kind] = states
+	}
+}
+
+// Kinds перечисляет типы фигур
+var Kinds = []string{"i", "j", "l", "o", "s", "t", "z"}
+
+// Shape возвращает матрицу фигуры в текущем положении
+func (p *Piece) Shape() [][]int {
+	return shapes[p.Kind][p.Rotation]
+}
+
+// moved возвращает копию фигуры, сдвинутую на (dx, dy)
+func (p *Piece) moved(dx, dy int) *Piece {
+	moved := *p
+	moved.X += dx
+	moved.Y += dy
+	return &moved
+}
+
+// newPiece создает фигуру заданного типа в точке появления
+func newPiece(kind string) *Piece {
+	shape := spawnShapes[kind]
+	p := &Piece{
+		Kind: kind,
+		X:    (Width - len(shape[0])) / 2,
+		Y:    0,
+	}
+	// Верхняя строка квадрата I пустая, поднимаем фигуру, чтобы она появлялась в первой строке поля
+	if kind == "i" {
+		p.Y = -1
+	}
+	return p
+}
@@ -0,0 +1,136 @@
+package engine
+
+import "math/rand"
+
+// Randomizer выдаёт последовательность типов фигур
+type Randomizer interface {
+	Next() string
+}
+
+// RandomizerKind определяет алгоритм выбора фигур
+type RandomizerKind int
+
+const (
+	RandomizerBag7 RandomizerKind = iota
+	RandomizerBag14
+	RandomizerTGM
+	RandomizerRandom
+)
+
+// RandomizerKinds перечисляет доступные алгоритмы в порядке показа в меню
+var RandomizerKinds = []RandomizerKind{RandomizerBag7, RandomizerBag14, RandomizerTGM, RandomizerRandom}
+
+// String возвращает название алгоритма
+func (k RandomizerKind) String() string {
+	switch k {
+	case RandomizerBag7:
+		return "7-bag"
+	case RandomizerBag14:
+		return "14-bag"
+	case RandomizerTGM:
+		return "TGM"
+	case RandomizerRandom:
+		return "Random"
+	}
+	return "?"
+}
+
+// NewRandomizer создает генератор фигур выбранного типа
+func NewRandomizer(kind RandomizerKind, rng *rand.Rand) Randomizer {
+	switch kind {
+	case RandomizerBag14:
+		return &bagRandomizer{rng: rng, copies: 2}
+	case RandomizerTGM:
+		return newHistoryRandomizer(rng)
+	case RandomizerRandom:
+		return &pureRandomizer{rng: rng}
+	default:
+		return &bagRandomizer{rng: rng, copies: 1}
+	}
+}
+
+// bagRandomizer выдаёт фигуры из перемешанного мешка, в котором каждая фигура
+// встречается copies раз, и заполняет мешок заново, когда он опустеет
+type bagRandomizer struct {
+	rng    *rand.Rand
+	copies int
+	bag    []string
+}
+
+// Next возвращает следующую фигуру из мешка
+func (r *bagRandomizer) Next() string {
+	if len(r.bag) == 0 {
+		for i := 0; i < r.copies; i++ {
+			r.bag = append(r.bag, Kinds...)
+		}
+		r.rng.Shuffle(len(r.bag), func(i, j int) {
+			r.bag[i], r.bag[j] = r.bag[j], r.bag[i]
+		})
+	}
+	kind := r.bag[0]
+	r.bag = r.bag[1:]
+	return kind
+}
+
+const (
+	historySize  = 4
+	historyRolls = 6
+)
+
+// historyRandomizer реализует алгоритм TGM: фигура перевыбирается до historyRolls раз,
+// если она есть среди последних historySize выданных
+type historyRandomizer struct {
+	rng     *rand.Rand
+	history []string
+	isFirst bool
+}
+
+// newHistoryRandomizer создает генератор с начальной историей Z S S Z
+func newHistoryRandomizer(rng *rand.Rand) *historyRandomizer {
+	return &historyRandomizer{
+		rng:     rng,
+		history: []string{"z", "s", "s", "z"},
+		isFirst: true,
+	}
+}
+
+// Next возвращает следующую фигуру с учётом истории
+func (r *historyRandomizer) Next() string {
+	var kind string
+	if r.isFirst {
+		// Первой фигурой никогда не бывают S, Z и O
+		first := []string{"i", "j", "l", "t"}
+		kind = first[r.rng.Intn(len(first))]
+		r.isFirst = false
+	} else {
+		for i := 0; i < historyRolls; i++ {
+			kind = Kinds[r.rng.Intn(len(Kinds))]
+			if !r.inHistory(kind) {
+				break
+			}
+		}
+	}
+	copy(r.history, r.history[1:])
+	r.history[len(r.history)-1] = kind
+	return kind
+}
+
+// inHistory сообщает, выдавалась ли фигура недавно
+func (r *historyRandomizer) inHistory(kind string) bool {
+	for _, k := range r.history {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// pureRandomizer выбирает каждую фигуру независимо и равновероятно
+type pureRandomizer struct {
+	rng *rand.Rand
+}
+
+// Next возвращает случайную фигуру
+func (r *pureRandomizer) Next() string {
+	return Kinds[r.rng.Intn(len(Kinds))]
+}
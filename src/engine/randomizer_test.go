@@ -0,0 +1,129 @@
+package engine
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// drawPieces возвращает n фигур генератора kind с сидом seed
+func drawPieces(kind RandomizerKind, seed uint64, n int) []string {
+	r := NewRandomizer(kind, newRand(seed))
+	pieces := make([]string, n)
+	for i := range pieces {
+		pieces[i] = r.Next()
+	}
+	return pieces
+}
+
+func TestBagRandomizer(t *testing.T) {
+	tests := []struct {
+		kind   RandomizerKind
+		copies int
+	}{
+		{RandomizerBag7, 1},
+		{RandomizerBag14, 2},
+	}
+	for _, tc := range tests {
+		t.Run(tc.kind.String(), func(t *testing.T) {
+			size := len(Kinds) * tc.copies
+			for seed := uint64(0); seed < 20; seed++ {
+				pieces := drawPieces(tc.kind, seed, size*10)
+				for start := 0; start < len(pieces); start += size {
+					bag := slices.Clone(pieces[start : start+size])
+					slices.Sort(bag)
+					for i, kind := range bag {
+						if want := Kinds[i/tc.copies]; kind != want {
+							t.Fatalf("сид %d, мешок с %d: %v — не по %d каждой фигуры", seed, start, pieces[start:start+size], tc.copies)
+						}
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestRandomizerDeterministic(t *testing.T) {
+	for _, kind := range RandomizerKinds {
+		t.Run(kind.String(), func(t *testing.T) {
+			a, b := drawPieces(kind, 12345, 200), drawPieces(kind, 12345, 200)
+			if !slices.Equal(a, b) {
+				t.Fatal("один и тот же сид дал разные последовательности")
+			}
+			if slices.Equal(a, drawPieces(kind, 12346, 200)) {
+				t.Error("разные сиды дали одинаковые последовательности")
+			}
+		})
+	}
+}
+
+// Последовательность при заданном сиде не должна меняться между версиями игры,
+// иначе старые записи партий разойдутся с движком
+func TestRandomizerGolden(t *testing.T) {
+	tests := []struct {
+		kind RandomizerKind
+		want string
+	}{
+		{RandomizerBag7, "ilzjtsoilstjoztsjilzo"},
+		{RandomizerBag14, "littjjozzosslijsotsli"},
+		{RandomizerTGM, "litozjsiotzsjltzojlsz"},
+		{RandomizerRandom, "izitttiotizijsjjiotiz"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.kind.String(), func(t *testing.T) {
+			if got := strings.Join(drawPieces(tc.kind, 1, len(tc.want)), ""); got != tc.want {
+				t.Errorf("сид 1 дал %s, ожидалось %s", got, tc.want)
+			}
+		})
+	}
+}
+
+// Первые фигуры партии берутся из генератора с тем же сидом
+func TestRandomizerSeed(t *testing.T) {
+	want := drawPieces(RandomizerBag7, 1, 1+MaxPreviews)
+	e := New(Config{Seed: 1, Previews: MaxPreviews})
+	if got := append([]string{e.Current().Kind}, e.Queue()...); !slices.Equal(got, want) {
+		t.Errorf("движок выдал %v, генератор с тем же сидом — %v", got, want)
+	}
+}
+
+func TestRandomizerDistribution(t *testing.T) {
+	const n = 70000
+	tests := []struct {
+		kind       RandomizerKind
+		maxRepeats float64 // доля фигур, совпадающих с предыдущей
+	}{
+		{RandomizerBag7, 1.0 / 7},
+		{RandomizerTGM, 0.02},
+		{RandomizerRandom, 0.16},
+	}
+	for _, tc := range tests {
+		t.Run(tc.kind.String(), func(t *testing.T) {
+			pieces := drawPieces(tc.kind, 2024, n)
+			counts := map[string]int{}
+			repeats := 0
+			for i, kind := range pieces {
+				counts[kind]++
+				if i > 0 && kind == pieces[i-1] {
+					repeats++
+				}
+			}
+			for _, kind := range Kinds {
+				if share := float64(counts[kind]) / n; share < 0.13 || share > 0.155 {
+					t.Errorf("доля %s = %.3f, ожидалось около 1/7", kind, share)
+				}
+			}
+			if share := float64(repeats) / n; share > tc.maxRepeats {
+				t.Errorf("повторов подряд %.3f, ожидалось не больше %.3f", share, tc.maxRepeats)
+			}
+		})
+	}
+}
+
+func TestHistoryRandomizerFirstPiece(t *testing.T) {
+	for seed := uint64(0); seed < 200; seed++ {
+		if first := drawPieces(RandomizerTGM, seed, 1)[0]; first == "s" || first == "z" || first == "o" {
+			t.Fatalf("сид %d: первая фигура %s", seed, first)
+		}
+	}
+}
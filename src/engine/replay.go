@@ -0,0 +1,290 @@
+package engine
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+)
+
+// replayMagic и replayVersion открывают файл записи партии
+const (
+	replayMagic   = "ZTR"
+	replayVersion = 1
+)
+
+// RulesVersion — версия правил движка. Её нужно повышать при любом изменении,
+// после которого прежние записи воспроизводятся иначе: такие записи и сохранённые
+// партии отвергаются, а не расходятся с итогом
+const RulesVersion = 1
+
+// ErrRulesVersion означает, что запись сделана на другой версии правил движка
+var ErrRulesVersion = errors.New("запись сделана на другой версии правил")
+
+// InputChange — момент, когда изменился набор удерживаемых кнопок
+type InputChange struct {
+	Frame int // кадр, начиная с которого действует Input
+	Input Input
+}
+
+// Replay — запись партии: всё, что нужно, чтобы воспроизвести её кадр в кадр
+type Replay struct {
+	Config  Config
+	Rules   int // RulesVersion движка, на котором сыграна партия
+	Player  string
+	Mode    string // режим партии в понимании интерфейса
+	Variant int    // вариант режима, например длительность или цель
+	Date    time.Time
+	Frames  int // сколько кадров длилась партия
+	Inputs  []InputChange
+	Result  Result // итог партии, с которым сверяется воспроизведение
+}
+
+// Result — итог партии, по которому проверяется запись
+type Result struct {
+	Score  int    `json:"score"`
+	Lines  int    `json:"lines"`
+	Frames int    `json:"frames"`
+	Board  string `json:"board"` // BoardHash итогового поля
+}
+
+// replayHeader — заголовок файла записи
+type replayHeader struct {
+	Config  Config    `json:"config"`
+	Rules   int       `json:"rules"`
+	Player  string    `json:"player"`
+	Mode    string    `json:"mode"`
+	Variant int       `json:"variant,omitempty"`
+	Date    time.Time `json:"date"`
+	Result  Result    `json:"result"`
+}
+
+// recordInput запоминает кнопки кадра, если они отличаются от предыдущего
+func (e *Engine) recordInput(in Input) {
+	if n := len(e.inputs); n > 0 && e.inputs[n-1].Input == in || n == 0 && in == 0 {
+		return
+	}
+	e.inputs = append(e.inputs, InputChange{Frame: e.frames, Input: in})
+}
+
+// Replay возвращает запись партии до текущего кадра
+func (e *Engine) Replay() *Replay {
+	return &Replay{
+		Config: e.config,
+		Rules:  RulesVersion,
+		Frames: e.frames,
+		Inputs: slices.Clone(e.inputs),
+		Result: e.Result(),
+	}
+}
+
+// Result возвращает итог партии на текущем кадре
+func (e *Engine) Result() Result {
+	return Result{Score: e.score, Lines: e.lines, Frames: e.frames, Board: e.BoardHash()}
+}
+
+// BoardHash возвращает SHA-256 содержимого поля построчно в шестнадцатеричном виде
+func (e *Engine) BoardHash() string {
+	h := sha256.New()
+	for _, row := range e.board {
+		for _, cell := range row {
+			h.Write([]byte(cell))
+			h.Write([]byte{0})
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Verify воспроизводит запись без отрисовки и сверяет итог с сохранённым в ней.
+// Возвращает итог воспроизведения и ошибку, если он расходится с записанным
+func (r *Replay) Verify() (Result, error) {
+	if err := r.CheckRules(); err != nil {
+		return Result{}, err
+	}
+	got := NewPlayer(r).Run().Result()
+	if got != r.Result {
+		return got, fmt.Errorf("итог не совпадает с записью: счёт %d/%d, линии %d/%d, кадры %d/%d, поле %.12s/%.12s",
+			got.Score, r.Result.Score, got.Lines, r.Result.Lines, got.Frames, r.Result.Frames, got.Board, r.Result.Board)
+	}
+	return got, nil
+}
+
+// CheckRules сообщает об ошибке, если запись сделана на другой версии правил
+// и не может быть воспроизведена этим движком
+func (r *Replay) CheckRules() error {
+	if r.Rules != RulesVersion {
+		return fmt.Errorf("%w: версия %d, а движок поддерживает версию %d", ErrRulesVersion, r.Rules, RulesVersion)
+	}
+	return nil
+}
+
+// InputAt возвращает кнопки, удерживаемые в кадре frame
+func (r *Replay) InputAt(frame int) Input {
+	i, found := slices.BinarySearchFunc(r.Inputs, frame, func(c InputChange, f int) int {
+		return c.Frame - f
+	})
+	if found {
+		return r.Inputs[i].Input
+	}
+	if i == 0 {
+		return 0
+	}
+	return r.Inputs[i-1].Input
+}
+
+// MarshalBinary кодирует запись: сигнатура и версия, заголовок в JSON,
+// затем длительность и изменения кнопок в виде разностей кадров в varint
+func (r *Replay) MarshalBinary() ([]byte, error) {
+	header, err := json.Marshal(replayHeader{Config: r.Config, Rules: r.Rules, Player: r.Player, Mode: r.Mode, Variant: r.Variant, Date: r.Date, Result: r.Result})
+	if err != nil {
+		return nil, err
+	}
+	buf := []byte(replayMagic)
+	buf = append(buf, replayVersion)
+	buf = binary.AppendUvarint(buf, uint64(len(header)))
+	buf = append(buf, header...)
+	buf = binary.AppendUvarint(buf, uint64(r.Frames))
+	buf = binary.AppendUvarint(buf, uint64(len(r.Inputs)))
+	last := 0
+	for _, c := range r.Inputs {
+		buf = binary.AppendUvarint(buf, uint64(c.Frame-last))
+		buf = append(buf, byte(c.Input))
+		last = c.Frame
+	}
+	return buf, nil
+}
+
+// UnmarshalBinary разбирает запись, закодированную MarshalBinary
+func (r *Replay) UnmarshalBinary(data []byte) error {
+	if !bytes.HasPrefix(data, []byte(replayMagic)) || len(data) < len(replayMagic)+1 {
+		return errors.New("не файл записи партии")
+	}
+	if v := data[len(replayMagic)]; v != replayVersion {
+		return fmt.Errorf("неизвестная версия записи %d", v)
+	}
+	rd := bytes.NewReader(data[len(replayMagic)+1:])
+	size, err := binary.ReadUvarint(rd)
+	if err != nil {
+		return err
+	}
+	if size > uint64(rd.Len()) {
+		return io.ErrUnexpectedEOF
+	}
+	header := make([]byte, size)
+	if _, err := io.ReadFull(rd, header); err != nil {
+		return err
+	}
+	var h replayHeader
+	if err := json.Unmarshal(header, &h); err != nil {
+		return err
+	}
+	frames, err := binary.ReadUvarint(rd)
+	if err != nil {
+		return err
+	}
+	count, err := binary.ReadUvarint(rd)
+	if err != nil {
+		return err
+	}
+	if count > uint64(rd.Len()) {
+		return io.ErrUnexpectedEOF
+	}
+	inputs := make([]InputChange, 0, count)
+	frame := 0
+	for range count {
+		delta, err := binary.ReadUvarint(rd)
+		if err != nil {
+			return err
+		}
+		in, err := rd.ReadByte()
+		if err != nil {
+			return err
+		}
+		frame += int(delta)
+		inputs = append(inputs, InputChange{Frame: frame, Input: Input(in)})
+	}
+	*r = Replay{Config: h.Config, Rules: h.Rules, Player: h.Player, Mode: h.Mode, Variant: h.Variant, Date: h.Date, Frames: int(frames), Inputs: inputs, Result: h.Result}
+	return nil
+}
+
+// Player воспроизводит запись партии на собственном движке
+type Player struct {
+	replay    *Replay
+	engine    *Engine
+	listeners []func(LockResult)
+}
+
+// NewPlayer создает проигрыватель, стоящий на первом кадре записи
+func NewPlayer(r *Replay) *Player {
+	p := &Player{replay: r}
+	p.rewind()
+	return p
+}
+
+// rewind начинает воспроизведение заново на новом движке
+func (p *Player) rewind() {
+	p.engine = New(p.replay.Config)
+	for _, listener := range p.listeners {
+		p.engine.Subscribe(listener)
+	}
+}
+
+// Engine возвращает движок, на котором идёт воспроизведение. После Seek назад он заменяется новым
+func (p *Player) Engine() *Engine {
+	return p.engine
+}
+
+// Replay возвращает воспроизводимую запись
+func (p *Player) Replay() *Replay {
+	return p.replay
+}
+
+// Subscribe добавляет обработчик фиксаций, который сохраняется при перемотке
+func (p *Player) Subscribe(listener func(LockResult)) {
+	p.listeners = append(p.listeners, listener)
+	p.engine.Subscribe(listener)
+}
+
+// Done сообщает, что запись воспроизведена до конца
+func (p *Player) Done() bool {
+	e := p.engine
+	if e.isOver {
+		return true
+	}
+	// Партия на время завершается отдельным вызовом Step после последнего кадра
+	if e.config.TimeLimit > 0 && e.frames >= e.config.TimeLimit {
+		return false
+	}
+	return e.frames >= p.replay.Frames
+}
+
+// Step продвигает воспроизведение на один кадр. Возвращает false, если запись закончилась
+func (p *Player) Step() bool {
+	if p.Done() {
+		return false
+	}
+	p.engine.Step(p.replay.InputAt(p.engine.frames))
+	return true
+}
+
+// Seek переходит к кадру frame. Назад движок перематывается пересчётом с начала записи
+func (p *Player) Seek(frame int) {
+	if frame < p.engine.frames {
+		p.rewind()
+	}
+	for p.engine.frames < frame && p.Step() {
+	}
+}
+
+// Run воспроизводит запись до конца и возвращает итоговый движок
+func (p *Player) Run() *Engine {
+	for p.Step() {
+	}
+	return p.engine
+}
@@ -0,0 +1,165 @@
+package engine
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// playRandom играет партию случайными, но воспроизводимыми нажатиями:
+// примерно раз в несколько кадров меняется набор кнопок, иногда с жёстким сбросом
+func playRandom(config Config, frames int, inputSeed uint64) *Engine {
+	e := New(config)
+	r := newRand(inputSeed)
+	var in Input
+	for i := 0; i < frames && !e.IsOver(); i++ {
+		if r.Intn(6) == 0 {
+			in = Input(r.Intn(int(InputHold) << 1))
+		}
+		e.Step(in)
+	}
+	return e
+}
+
+// replayConfigs — партии разных режимов, на которых проверяются записи
+var replayConfigs = []struct {
+	name   string
+	config Config
+}{
+	{"default", Config{Seed: 1, LinesPerLevel: 10}},
+	{"ultra", Config{Seed: 2, TimeLimit: 300, LinesPerLevel: 10}},
+	{"dig", Config{Seed: 3, DigGoal: 10, GarbageHeight: 10, Messiness: 0.5}},
+	{"survival", Config{Seed: 4, RiseInterval: 60}},
+	{"master", Config{Seed: 5, Level: len(GravityCurve), Randomizer: RandomizerTGM, Ruleset: RulesetTGM, LockPolicy: LockStepReset, ARE: 30, LineClearDelay: 41}},
+	{"classic", Config{Seed: 6, Level: 5, Ruleset: RulesetClassic, DisableHold: true, Previews: 1}},
+}
+
+func TestReplayRoundTrip(t *testing.T) {
+	for _, tc := range replayConfigs {
+		t.Run(tc.name, func(t *testing.T) {
+			e := playRandom(tc.config, 3000, 42)
+			replay := e.Replay()
+			replay.Player = "тест"
+			replay.Mode = tc.name
+			replay.Variant = 2
+			replay.Date = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+			data, err := replay.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got Replay
+			if err := got.UnmarshalBinary(data); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(&got, replay) {
+				t.Fatalf("запись после разбора отличается:\nполучено %+v\nожидалось %+v", got, *replay)
+			}
+			result, err := got.Verify()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result != e.Result() {
+				t.Errorf("Verify = %+v, ожидалось %+v", result, e.Result())
+			}
+		})
+	}
+}
+
+func TestReplayVerifyTampered(t *testing.T) {
+	e := playRandom(Config{Seed: 7, LinesPerLevel: 10}, 3000, 43)
+	if e.Pieces() < 2 {
+		t.Fatalf("партия слишком короткая для проверки: %d фигур", e.Pieces())
+	}
+	tests := []struct {
+		name   string
+		tamper func(r *Replay)
+	}{
+		{"score", func(r *Replay) { r.Result.Score++ }},
+		{"lines", func(r *Replay) { r.Result.Lines++ }},
+		{"frames", func(r *Replay) { r.Result.Frames-- }},
+		{"board", func(r *Replay) { r.Result.Board = New(Config{}).BoardHash() }},
+		{"seed", func(r *Replay) { r.Config.Seed++ }},
+		{"inputs", func(r *Replay) { r.Inputs = r.Inputs[:len(r.Inputs)/2] }},
+		{"rules", func(r *Replay) { r.Rules = RulesVersion + 1 }},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			replay := e.Replay()
+			tc.tamper(replay)
+			data, err := replay.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got Replay
+			if err := got.UnmarshalBinary(data); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := got.Verify(); err == nil {
+				t.Error("Verify не заметил подмены")
+			}
+		})
+	}
+}
+
+// Запись без версии правил или с чужой версией не воспроизводится
+func TestReplayRulesVersion(t *testing.T) {
+	for _, rules := range []int{0, RulesVersion + 1} {
+		replay := playRandom(Config{Seed: 10}, 600, 46).Replay()
+		replay.Rules = rules
+		data, err := replay.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got Replay
+		if err := got.UnmarshalBinary(data); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := got.Verify(); !errors.Is(err, ErrRulesVersion) {
+			t.Errorf("версия правил %d: Verify вернул %v, ожидалась ErrRulesVersion", rules, err)
+		}
+	}
+}
+
+func TestReplayUnmarshalRejects(t *testing.T) {
+	data, err := playRandom(Config{Seed: 8}, 600, 44).Replay().MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wrongVersion := append([]byte(nil), data...)
+	wrongVersion[len(replayMagic)]++
+	tests := map[string][]byte{
+		"empty":     nil,
+		"magic":     append([]byte("ZIP"), data[len(replayMagic):]...),
+		"version":   wrongVersion,
+		"truncated": data[:len(data)-1],
+		"header":    data[:len(replayMagic)+3],
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			var r Replay
+			if err := r.UnmarshalBinary(data); err == nil {
+				t.Error("ожидалась ошибка разбора")
+			}
+		})
+	}
+}
+
+func TestPlayerSeek(t *testing.T) {
+	e := playRandom(Config{Seed: 9, LinesPerLevel: 10, ARE: 10}, 2000, 45)
+	replay := e.Replay()
+	player := NewPlayer(replay)
+	for _, frame := range []int{1500, 300, 1000, 0, replay.Frames} {
+		player.Seek(frame)
+		want := NewPlayer(replay)
+		for want.Engine().Frames() < frame && want.Step() {
+		}
+		if got := player.Engine().Result(); got != want.Engine().Result() {
+			t.Errorf("Seek(%d) = %+v, ожидалось %+v", frame, got, want.Engine().Result())
+		}
+	}
+	if !player.Done() || player.Engine().Result() != e.Result() {
+		t.Errorf("после перемотки в конец итог %+v, ожидалось %+v", player.Engine().Result(), e.Result())
+	}
+}
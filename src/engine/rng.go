@@ -0,0 +1,34 @@
+package engine
+
+import "math/rand"
+
+// splitMix — генератор SplitMix64. В отличие от стандартного источника его
+// состояние целиком задаётся одним 64-битным числом, поэтому партия с тем же
+// сидом воспроизводится одинаково на любой платформе и версии Go
+type splitMix struct {
+	state uint64
+}
+
+// Seed устанавливает состояние генератора
+func (s *splitMix) Seed(seed int64) {
+	s.state = uint64(seed)
+}
+
+// Uint64 возвращает следующее псевдослучайное число
+func (s *splitMix) Uint64() uint64 {
+	s.state += 0x9e3779b97f4a7c15
+	z := s.state
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+// Int63 возвращает неотрицательное 63-битное число
+func (s *splitMix) Int63() int64 {
+	return int64(s.Uint64() >> 1)
+}
+
+// newRand создает генератор, полностью определяемый сидом
+func newRand(seed uint64) *rand.Rand {
+	return rand.New(&splitMix{state: seed})
+}
@@ -0,0 +1,75 @@
+package engine
+
+// Rotation — положение фигуры в системе SRS
+type Rotation int
+
+const (
+	Rotation0 Rotation = iota // положение появления
+	RotationR                 // повёрнута по часовой стрелке
+	Rotation2                 // повёрнута дважды
+	RotationL                 // повёрнута против часовой стрелки
+)
+
+// kick — смещение при попытке поворота. В таблицах ниже y направлен вверх,
+// как в описании SRS; при применении знак y меняется
+type kick struct {
+	x, y int
+}
+
+// jlstzKicks — таблица смещений для J, L, S, T и Z
+var jlstzKicks = map[[2]Rotation][]kick{
+	{Rotation0, RotationR}: {{0, 0}, {-1, 0}, {-1, 1}, {0, -2}, {-1, -2}},
+	{RotationR, Rotation0}: {{0, 0}, {1, 0}, {1, -1}, {0, 2}, {1, 2}},
+	{RotationR, Rotation2}: {{0, 0}, {1, 0}, {1, -1}, {0, 2}, {1, 2}},
+	{Rotation2, RotationR}: {{0, 0}, {-1, 0}, {-1, 1}, {0, -2}, {-1, -2}},
+	{Rotation2, RotationL}: {{0, 0}, {1, 0}, {1, 1}, {0, -2}, {1, -2}},
+	{RotationL, Rotation2}: {{0, 0}, {-1, 0}, {-1, -1}, {0, 2}, {-1, 2}},
+	{RotationL, Rotation0}: {{0, 0}, {-1, 0}, {-1, -1}, {0, 2}, {-1, 2}},
+	{Rotation0, RotationL}: {{0, 0}, {1, 0}, {1, 1}, {0, -2}, {1, -2}},
+}
+
+// iKicks — отдельная таблица смещений для I
+var iKicks = map[[2]Rotation][]kick{
+	{Rotation0, RotationR}: {{0, 0}, {-2, 0}, {1, 0}, {-2, -1}, {1, 2}},
+	{RotationR, Rotation0}: {{0, 0}, {2, 0}, {-1, 0}, {2, 1}, {-1, -2}},
+	{RotationR, Rotation2}: {{0, 0}, {-1, 0}, {2, 0}, {-1, 2}, {2, -1}},
+	{Rotation2, RotationR}: {{0, 0}, {1, 0}, {-2, 0}, {1, -2}, {-2, 1}},
+	{Rotation2, RotationL}: {{0, 0}, {2, 0}, {-1, 0}, {2, 1}, {-1, -2}},
+	{RotationL, Rotation2}: {{0, 0}, {-2, 0}, {1, 0}, {-2, -1}, {1, 2}},
+	{RotationL, Rotation0}: {{0, 0}, {1, 0}, {-2, 0}, {1, -2}, {-2, 1}},
+	{Rotation0, RotationL}: {{0, 0}, {-1, 0}, {2, 0}, {-1, 2}, {2, -1}},
+}
+
+// oKicks — O при повороте не смещается
+var oKicks = []kick{{0, 0}}
+
+// turned возвращает положение после поворота на dir четвертей (1 — по часовой, -1 — против)
+func (r Rotation) turned(dir int) Rotation {
+	return Rotation(((int(r)+dir)%4 + 4) % 4)
+}
+
+// kicksFor возвращает список смещений для поворота фигуры из from в to
+func kicksFor(kind string, from, to Rotation) []kick {
+	switch kind {
+	case "o":
+		return oKicks
+	case "i":
+		return iKicks[[2]Rotation{from, to}]
+	default:
+		return jlstzKicks[[2]Rotation{from, to}]
+	}
+}
+
+// rotatedClockwise возвращает матрицу, повёрнутую по часовой стрелке
+func rotatedClockwise(shape [][]int) [][]int {
+	newShape := make([][]int, len(shape[0]))
+	for i := range newShape {
+		newShape[i] = make([]int, len(shape))
+	}
+	for i := 0; i < len(shape); i++ {
+		for j := 0; j < len(shape[0]); j++ {
+			newShape[j][len(shape)-1-i] = shape[i][j]
+		}
+	}
+	return newShape
+}
@@ -0,0 +1,104 @@
+package engine
+
+import "testing"
+
+// SRS симметрична: смещения обратного поворота противоположны прямому
+func TestKickTablesSymmetric(t *testing.T) {
+	for _, kind := range Kinds {
+		for from := Rotation0; from <= RotationL; from++ {
+			for _, dir := range []int{1, -1} {
+				to := from.turned(dir)
+				forward, backward := kicksFor(kind, from, to), kicksFor(kind, to, from)
+				want := 5
+				if kind == "o" {
+					want = 1
+				}
+				if len(forward) != want || len(backward) != want {
+					t.Fatalf("%s %d→%d: %d и %d смещений, ожидалось %d", kind, from, to, len(forward), len(backward), want)
+				}
+				for i := range forward {
+					if forward[i].x != -backward[i].x || forward[i].y != -backward[i].y {
+						t.Errorf("%s %d→%d: смещение %d %v не противоположно обратному %v", kind, from, to, i, forward[i], backward[i])
+					}
+				}
+				if forward[0] != (kick{}) {
+					t.Errorf("%s %d→%d: первое смещение %v, ожидался поворот на месте", kind, from, to, forward[0])
+				}
+			}
+		}
+	}
+}
+
+func TestTurned(t *testing.T) {
+	tests := []struct {
+		from Rotation
+		dir  int
+		want Rotation
+	}{
+		{Rotation0, 1, RotationR},
+		{RotationR, 1, Rotation2},
+		{RotationL, 1, Rotation0},
+		{Rotation0, -1, RotationL},
+		{Rotation2, -1, RotationR},
+		{Rotation0, 2, Rotation2},
+	}
+	for _, tc := range tests {
+		if got := tc.from.turned(tc.dir); got != tc.want {
+			t.Errorf("%d.turned(%d) = %d, ожидалось %d", tc.from, tc.dir, got, tc.want)
+		}
+	}
+}
+
+func TestRotateKicks(t *testing.T) {
+	full := make([]string, Height)
+	for i := range full {
+		full[i] = "XXXXXXXXXX"
+	}
+	tests := []struct {
+		name           string
+		rows           []string
+		kind           string
+		from           Rotation
+		x, y, dir      int
+		wantKick       int
+		wantX, wantY   int
+		wantRotation   Rotation
+		clearPieceArea bool
+	}{
+		{name: "на месте", kind: "t", from: Rotation0, x: 3, y: 5, dir: 1,
+			wantKick: 0, wantX: 3, wantY: 5, wantRotation: RotationR},
+		{name: "от левой стены", kind: "t", from: RotationR, x: -1, y: 5, dir: -1,
+			wantKick: 1, wantX: 0, wantY: 5, wantRotation: Rotation0},
+		{name: "I от пола", kind: "i", from: Rotation0, x: 3, y: Height - 2, dir: 1,
+			wantKick: 4, wantX: 4, wantY: Height - 4, wantRotation: RotationR},
+		{name: "O не смещается", kind: "o", from: Rotation0, x: 4, y: 5, dir: 1,
+			wantKick: 0, wantX: 4, wantY: 5, wantRotation: RotationR},
+		{name: "некуда повернуть", rows: full, kind: "t", from: Rotation0, x: 3, y: 5, dir: 1,
+			wantKick: -1, wantX: 3, wantY: 5, wantRotation: Rotation0, clearPieceArea: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			e := New(Config{Seed: 1})
+			setRows(e, tc.rows...)
+			setCurrent(e, tc.kind, tc.from, tc.x, tc.y)
+			if tc.clearPieceArea {
+				for i, row := range e.current.Shape() {
+					for j, cell := range row {
+						if cell != 0 {
+							e.board[tc.y+i][tc.x+j] = ""
+						}
+					}
+				}
+			}
+			if e.board.collides(e.current) {
+				t.Fatal("фигура пересекается с полем до поворота")
+			}
+			got := e.rotate(tc.dir)
+			p := e.Current()
+			if got != tc.wantKick || p.X != tc.wantX || p.Y != tc.wantY || p.Rotation != tc.wantRotation {
+				t.Errorf("rotate = %d, фигура в (%d, %d) положение %d; ожидалось %d, (%d, %d) положение %d",
+					got, p.X, p.Y, p.Rotation, tc.wantKick, tc.wantX, tc.wantY, tc.wantRotation)
+			}
+		})
+	}
+}
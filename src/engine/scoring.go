@@ -0,0 +1,152 @@
+package engine
+
+// Ruleset определяет систему начисления очков
+type Ruleset int
+
+const (
+	RulesetGuideline Ruleset = iota // современные правила: T-spin, комбо, back-to-back, perfect clear
+	RulesetClassic                  // NES: очки только за линии
+	RulesetTGM                      // формула TGM: комбо, бонус за сброс и полную очистку
+)
+
+// Rulesets перечисляет системы очков в порядке показа в меню
+var Rulesets = []Ruleset{RulesetGuideline, RulesetClassic, RulesetTGM}
+
+// String возвращает название системы очков
+func (r Ruleset) String() string {
+	switch r {
+	case RulesetGuideline:
+		return "Guideline"
+	case RulesetClassic:
+		return "NES"
+	case RulesetTGM:
+		return "TGM"
+	}
+	return "?"
+}
+
+// Очки по правилам Guideline за 0–4 линии, умножаются на уровень
+var (
+	guidelineLinePoints      = [5]int{0, 100, 300, 500, 800}
+	guidelineTSpinPoints     = [5]int{400, 800, 1200, 1600, 1600}
+	guidelineTSpinMiniPoints = [5]int{100, 200, 400, 400, 400}
+	guidelinePerfectPoints   = [5]int{0, 800, 1200, 1800, 2000}
+	classicLinePoints        = [5]int{0, 40, 100, 300, 1200}
+)
+
+const (
+	guidelineComboPoints    = 50
+	guidelinePerfectB2B     = 3200
+	guidelineSoftDropPoints = 1
+	guidelineHardDropPoints = 2
+	tgmBravo                = 4
+)
+
+// scoring хранит состояние начисления очков между фиксациями
+type scoring struct {
+	ruleset  Ruleset
+	combo    int // число очисток подряд минус один, -1 — серии нет
+	b2b      int // число сложных очисток подряд минус один, -1 — серии нет
+	tgmCombo int
+	soft     int // клеток мягкого сброса текущей фигуры, для TGM
+	sonic    int // клеток жёсткого сброса текущей фигуры, для TGM
+}
+
+// newScoring создает подсчёт очков для выбранных правил
+func newScoring(ruleset Ruleset) scoring {
+	return scoring{ruleset: ruleset, combo: -1, b2b: -1, tgmCombo: 1}
+}
+
+// drop возвращает очки за сброс фигуры на cells клеток
+func (s *scoring) drop(cells int, hard bool) int {
+	switch s.ruleset {
+	case RulesetGuideline:
+		if hard {
+			return cells * guidelineHardDropPoints
+		}
+		return cells * guidelineSoftDropPoints
+	case RulesetClassic:
+		if hard {
+			return 0
+		}
+		return cells
+	case RulesetTGM:
+		// В TGM сброс учитывается в формуле при очистке линий
+		if hard {
+			s.sonic += cells
+		} else {
+			s.soft += cells
+		}
+	}
+	return 0
+}
+
+// lock начисляет очки за фиксацию и дополняет r сведениями о комбо и back-to-back
+func (s *scoring) lock(r *LockResult, level int) int {
+	switch s.ruleset {
+	case RulesetClassic:
+		r.Combo = -1
+		return classicLinePoints[r.Lines] * level
+	case RulesetTGM:
+		return s.lockTGM(r, level)
+	}
+	return s.lockGuideline(r, level)
+}
+
+// lockGuideline считает очки по правилам Guideline
+func (s *scoring) lockGuideline(r *LockResult, level int) int {
+	var points int
+	switch r.TSpin {
+	case TSpinFull:
+		points = guidelineTSpinPoints[r.Lines]
+	case TSpinMini:
+		points = guidelineTSpinMiniPoints[r.Lines]
+	default:
+		points = guidelineLinePoints[r.Lines]
+	}
+	if r.Lines == 0 {
+		s.combo = -1
+		r.Combo = s.combo
+		return points * level
+	}
+
+	s.combo++
+	if r.Lines == 4 || r.TSpin != TSpinNone {
+		s.b2b++
+		if s.b2b > 0 {
+			points = points * 3 / 2
+			r.BackToBack = true
+		}
+	} else {
+		s.b2b = -1
+	}
+	points += guidelineComboPoints * s.combo
+	if r.PerfectClear {
+		if r.Lines == 4 && r.BackToBack {
+			points += guidelinePerfectB2B
+		} else {
+			points += guidelinePerfectPoints[r.Lines]
+		}
+	}
+	r.Combo = s.combo
+	return points * level
+}
+
+// lockTGM считает очки по формуле TGM:
+// (⌈(уровень + линии) / 4⌉ + мягкий сброс + жёсткий сброс) × линии × комбо × бонус за полную очистку
+func (s *scoring) lockTGM(r *LockResult, level int) int {
+	soft, sonic := s.soft, s.sonic
+	s.soft, s.sonic = 0, 0
+	if r.Lines == 0 {
+		s.tgmCombo = 1
+		r.Combo = -1
+		return 0
+	}
+	s.tgmCombo += 2*r.Lines - 2
+	bravo := 1
+	if r.PerfectClear {
+		bravo = tgmBravo
+	}
+	r.Combo = s.tgmCombo - 1
+	return ((level+r.Lines+3)/4 + soft + sonic) * r.Lines * s.tgmCombo * bravo
+}
@@ -0,0 +1,105 @@
+package engine
+
+import "testing"
+
+// scoringStep — одна фиксация в серии: сброс перед ней, её итог и ожидаемые очки
+type scoringStep struct {
+	soft, hard int // клеток мягкого и жёсткого сброса перед фиксацией
+	lines      int
+	tspin      TSpin
+	perfect    bool
+	points     int // очки за фиксацию без учёта сброса
+	dropPoints int // очки, начисленные сразу за сброс
+	combo      int
+	b2b        bool
+}
+
+func TestScoring(t *testing.T) {
+	tests := []struct {
+		name    string
+		ruleset Ruleset
+		level   int
+		steps   []scoringStep
+	}{
+		{"guideline: single", RulesetGuideline, 1, []scoringStep{
+			{lines: 1, points: 100, combo: 0},
+		}},
+		{"guideline: уровень умножает очки", RulesetGuideline, 3, []scoringStep{
+			{lines: 1, points: 300, combo: 0},
+		}},
+		{"guideline: сброс", RulesetGuideline, 1, []scoringStep{
+			{soft: 4, hard: 10, points: 0, dropPoints: 4 + 20, combo: -1},
+		}},
+		{"guideline: back-to-back тетрисы", RulesetGuideline, 1, []scoringStep{
+			{lines: 4, points: 800, combo: 0},
+			{lines: 4, points: 1200 + 50, combo: 1, b2b: true},
+			{lines: 4, points: 1200 + 100, combo: 2, b2b: true},
+		}},
+		{"guideline: обычная очистка прерывает back-to-back", RulesetGuideline, 1, []scoringStep{
+			{lines: 4, points: 800, combo: 0},
+			{lines: 1, points: 100 + 50, combo: 1},
+			{lines: 4, points: 800 + 100, combo: 2},
+		}},
+		{"guideline: фиксация без линий прерывает комбо, но не back-to-back", RulesetGuideline, 1, []scoringStep{
+			{lines: 4, points: 800, combo: 0},
+			{lines: 0, points: 0, combo: -1},
+			{lines: 4, points: 1200, combo: 0, b2b: true},
+		}},
+		{"guideline: T-spin", RulesetGuideline, 1, []scoringStep{
+			{tspin: TSpinFull, lines: 0, points: 400, combo: -1},
+			{tspin: TSpinFull, lines: 2, points: 1200, combo: 0},
+			{tspin: TSpinMini, lines: 1, points: 300 + 50, combo: 1, b2b: true},
+			{tspin: TSpinFull, lines: 3, points: 2400 + 100, combo: 2, b2b: true},
+		}},
+		{"guideline: perfect clear", RulesetGuideline, 1, []scoringStep{
+			{lines: 1, perfect: true, points: 100 + 800, combo: 0},
+			{lines: 4, perfect: true, points: 800 + 50 + 2000, combo: 1},
+			{lines: 4, perfect: true, points: 1200 + 100 + 3200, combo: 2, b2b: true},
+		}},
+		{"NES", RulesetClassic, 2, []scoringStep{
+			{lines: 1, points: 80, combo: -1},
+			{lines: 4, points: 2400, combo: -1},
+			{lines: 4, tspin: TSpinFull, points: 2400, combo: -1},
+			{soft: 7, hard: 10, lines: 2, points: 200, dropPoints: 7, combo: -1},
+		}},
+		{"TGM", RulesetTGM, 10, []scoringStep{
+			// (⌈(10 + 1) / 4⌉ + 0) × 1 × 1
+			{lines: 1, points: 3, combo: 0},
+			// комбо 1 + 2×2 − 2 = 3: (⌈12/4⌉ + 5) × 2 × 3
+			{hard: 5, lines: 2, points: 48, combo: 2},
+			{lines: 0, points: 0, combo: -1},
+			// полная очистка умножает на 4: (⌈14/4⌉ + 2) × 4 × 7 × 4
+			{soft: 2, lines: 4, perfect: true, points: 672, combo: 6},
+		}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := newScoring(tc.ruleset)
+			for i, step := range tc.steps {
+				drop := s.drop(step.soft, false) + s.drop(step.hard, true)
+				if drop != step.dropPoints {
+					t.Errorf("фиксация %d: за сброс %d очков, ожидалось %d", i, drop, step.dropPoints)
+				}
+				r := LockResult{Lines: step.lines, TSpin: step.tspin, PerfectClear: step.perfect}
+				if got := s.lock(&r, tc.level); got != step.points {
+					t.Errorf("фиксация %d: %d очков, ожидалось %d", i, got, step.points)
+				}
+				if r.Combo != step.combo || r.BackToBack != step.b2b {
+					t.Errorf("фиксация %d: комбо %d, back-to-back %v; ожидалось %d, %v", i, r.Combo, r.BackToBack, step.combo, step.b2b)
+				}
+			}
+		})
+	}
+}
+
+// Полная очистка поля распознаётся движком при фиксации
+func TestPerfectClearLock(t *testing.T) {
+	e := New(Config{Seed: 1})
+	setRows(e, "XXXXXX....")
+	setCurrent(e, "i", Rotation0, 6, Height-2)
+	e.lockPiece()
+	r := e.LastLock()
+	if !r.PerfectClear || r.Lines != 1 || r.Points != 100+800 {
+		t.Errorf("фиксация %+v, ожидалась полная очистка single на 900 очков", r)
+	}
+}
@@ -0,0 +1,52 @@
+package engine
+
+// TSpin — вид T-spin, выполненного при фиксации фигуры
+type TSpin int
+
+const (
+	TSpinNone TSpin = iota
+	TSpinMini
+	TSpinFull
+)
+
+// lastKickTST — номер последнего смещения SRS. Поворот с ним превращает
+// mini T-spin в полный даже при одном занятом переднем углу
+const lastKickTST = 4
+
+// tCorners — углы ограничивающего квадрата T в порядке:
+// левый верхний, правый верхний, левый нижний, правый нижний
+var tCorners = [4][2]int{{0, 0}, {2, 0}, {0, 2}, {2, 2}}
+
+// tFrontCorners — индексы углов со стороны, куда смотрит вершина T, для каждого положения
+var tFrontCorners = [4][2]int{
+	Rotation0: {0, 1},
+	RotationR: {1, 3},
+	Rotation2: {2, 3},
+	RotationL: {0, 2},
+}
+
+// detectTSpin определяет T-spin по правилу трёх углов. Последним успешным действием
+// должен быть поворот, иначе T-spin не засчитывается
+func (e *Engine) detectTSpin() TSpin {
+	p := e.current
+	if p.Kind != "t" || e.lastKick < 0 {
+		return TSpinNone
+	}
+	var occupied [4]bool
+	count := 0
+	for i, c := range tCorners {
+		x, y := p.X+c[0], p.Y+c[1]
+		if x < 0 || x >= Width || y >= Height || e.board.Cell(x, y) != "" {
+			occupied[i] = true
+			count++
+		}
+	}
+	if count < 3 {
+		return TSpinNone
+	}
+	front := tFrontCorners[p.Rotation]
+	if occupied[front[0]] && occupied[front[1]] || e.lastKick == lastKickTST {
+		return TSpinFull
+	}
+	return TSpinMini
+}
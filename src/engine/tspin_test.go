@@ -0,0 +1,67 @@
+package engine
+
+import "testing"
+
+func TestDetectTSpin(t *testing.T) {
+	// Щель под T-spin double: T вершиной вниз, оба нижних угла и левый верхний заняты
+	tsdSlot := []string{
+		"XXXXX.....",
+		"XXXX...XXX",
+		"XXXXX.XXXX",
+	}
+	// T вершиной вверх на полу: занят один передний угол и оба задних
+	miniSlot := []string{
+		"XXXX......",
+		"XXX...XXXX",
+		"XXXXXXXXXX",
+	}
+	tests := []struct {
+		name     string
+		rows     []string
+		kind     string
+		rotation Rotation
+		x, y     int
+		lastKick int
+		want     TSpin
+	}{
+		{"T-spin", tsdSlot, "t", Rotation2, 4, Height - 3, 0, TSpinFull},
+		{"без поворота", tsdSlot, "t", Rotation2, 4, Height - 3, -1, TSpinNone},
+		{"mini", miniSlot, "t", Rotation0, 3, Height - 3, 0, TSpinMini},
+		{"mini с последним смещением", miniSlot, "t", Rotation0, 3, Height - 3, lastKickTST, TSpinFull},
+		{"два угла", []string{"XXXXXXXXXX"}, "t", Rotation0, 3, Height - 3, 0, TSpinNone},
+		{"стена считается занятой", []string{"..........", "..........", ".XXXXXXXXX"}, "t", RotationR, -1, Height - 3, 0, TSpinMini},
+		{"не T", []string{"XXX..X....", "XXX...XXXX", "XXXXXXXXXX"}, "j", Rotation0, 3, Height - 3, 0, TSpinNone},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			e := New(Config{Seed: 1})
+			setRows(e, tc.rows...)
+			setCurrent(e, tc.kind, tc.rotation, tc.x, tc.y)
+			e.lastKick = tc.lastKick
+			if e.board.collides(e.current) {
+				t.Fatal("фигура пересекается с полем")
+			}
+			if got := e.detectTSpin(); got != tc.want {
+				t.Errorf("detectTSpin = %d, ожидалось %d", got, tc.want)
+			}
+		})
+	}
+}
+
+// T-spin распознаётся при фиксации и попадает в LockResult вместе с очками
+func TestTSpinLock(t *testing.T) {
+	e := New(Config{Seed: 1})
+	setRows(e,
+		"XXXXX.....",
+		"XXXX...XXX",
+		"XXXXX.XXXX",
+	)
+	// T только что повернулась в щель вершиной вниз
+	setCurrent(e, "t", Rotation2, 4, Height-3)
+	e.lastKick = 0
+	e.lockPiece()
+	r := e.LastLock()
+	if r.TSpin != TSpinFull || r.Lines != 2 || r.Points != guidelineTSpinPoints[2] {
+		t.Errorf("фиксация %+v, ожидался T-spin double на %d очков", r, guidelineTSpinPoints[2])
+	}
+}
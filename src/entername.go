@@ -13,6 +13,7 @@ type EnterNameScreen struct {
 	input        string
 	nextState    GameState
 	isCustomMode bool
+	start        func() // запускает выбранный режим после ввода имени
 }
 
 // NewEnterNameScreen создает новый экран ввода имени
@@ -22,6 +23,7 @@ func NewEnterNameScreen(game *Game) *EnterNameScreen {
 		input:        "",
 		nextState:    StateGame,
 		isCustomMode: false,
+		start:        func() { game.startMode(ModeSprint) },
 	}
 }
 
@@ -33,7 +35,7 @@ func (ens *EnterNameScreen) Update() error {
 	for _, key := range inpututil.AppendJustPressedKeys(nil) {
 		if key >= ebiten.KeyA && key <= ebiten.KeyZ {
 			if len(ens.input) < 10 { // Ограничение длины имени
-				ens.input += strings.ToUpper(string('A' + key - ebiten.KeyA))
+				ens.input += strings.ToUpper(string(rune('A' + key - ebiten.KeyA)))
 			}
 		}
 		if key == ebiten.KeyBackspace && len(ens.input) > 0 {
@@ -45,11 +47,13 @@ func (ens *EnterNameScreen) Update() error {
 		if ens.isCustomMode {
 			ens.game.customPlayerName = ens.input
 			ens.game.customNameEntered = true
+			ens.game.selectProfile(ens.input)
 			ens.game.state = StateCustomMode
 		} else {
 			ens.game.classicPlayerName = ens.input
 			ens.game.classicNameEntered = true
-			ens.game.start40Lines()
+			ens.game.selectProfile(ens.input)
+			ens.start()
 		}
 		ens.input = ""
 	}
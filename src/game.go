@@ -2,61 +2,44 @@ package src
 
 import (
 	"fmt"
+	"github.com/Xu3is/Zetris/src/engine"
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/audio"
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/hajimehoshi/ebiten/v2/text/v2"
 	"image/color"
-	"time"
+	"math/rand"
+	"sort"
+	"strconv"
 )
 
 const (
-	ScreenWidth       = 600
-	ScreenHeight      = 600
-	gridWidth         = 10
-	gridHeight        = 20
-	cellSize          = 24
-	keyRepeatDelay    = 150 * time.Millisecond
-	keyRepeatInterval = 50 * time.Millisecond
-	lockDelayDefault  = 500 * time.Millisecond
-	lockDelayLimit    = 5 * time.Second
+	ScreenWidth  = 600
+	ScreenHeight = 600
+	gridWidth    = engine.Width
+	gridHeight   = engine.Height
+	cellSize     = 24
+	previewSize  = cellSize * 3
 )
 
-type SpeedLevel struct {
-	fallSpeed float64
-	level     int
-}
-
-type HighScore struct {
-	Name  string
-	Score int
-}
-
-var speedLevels = []SpeedLevel{
-	{fallSpeed: 0.1, level: 1},
-	{fallSpeed: 0.0667, level: 2},
-	{fallSpeed: 0.05, level: 3},
-	{fallSpeed: 0.04, level: 4},
-	{fallSpeed: 0.0333, level: 5},
-}
-
 type Game struct {
 	settingsMenu       *SettingsMenu
-	grid               [gridHeight][gridWidth]string
-	currentPiece       *Piece
-	nextPiece          *Piece
-	score              int
-	fallTime           float64
-	fallSpeed          float64
+	engine             *engine.Engine
+	config             engine.Config
+	bindings           keyBindings
+	previews           int
+	showGhost          bool
+	ghostOpacity       float32
+	handling           engine.Handling
+	lockLabel          string
+	lockLabelTimer     int
+	seed               uint64
+	isSeedFixed        bool   // сид задан флагом командной строки для всех партий
+	customSeed         uint64 // сид, введённый в пользовательском режиме
+	isCustomSeedFixed  bool
 	isPaused           bool
-	isGameOver         bool
 	images             map[string]*ebiten.Image
 	font               *text.GoTextFace
-	lastUpdate         time.Time
-	keyLastAction      map[ebiten.Key]time.Time
-	keyPressStart      map[ebiten.Key]time.Time
-	lockDelayStart     time.Time
-	isPieceGrounded    bool
 	state              GameState
 	lastState          GameState
 	menu               *Menu
@@ -64,9 +47,17 @@ type Game struct {
 	customMode         *CustomMode
 	enterName          *EnterNameScreen
 	highScoreScreen    *HighScoreScreen
-	isLimitedTo40Lines bool
-	isCustomSpeed      bool
-	clearedLines       int
+	replayBrowser      *ReplayBrowser
+	replayViewer       *ReplayViewer
+	replayName         string // файл записи закончившейся партии
+	mode               Mode
+	variant            int     // вариант режима идущей партии, см. selectedVariant
+	marathonGoal       int     // цель марафона в линиях, выбранная в меню, 0 — бесконечный
+	digGoal            int     // сколько мусорных линий нужно очистить в режиме копания, выбирается в меню
+	messiness          float64 // вероятность смены столбца дыры в мусоре, настраивается в меню настроек
+	fadeFrames         int     // через сколько кадров исчезают клетки в режиме невидимки, выбирается в меню, 0 — сразу
+	flashTimer         int     // кадров до конца вспышки стека после очистки линий
+	ultraMinutes       int     // длительность Ultra, настраивается в меню настроек
 	menuPlayer         *audio.Player
 	customPlayer       *audio.Player
 	gamePlayer         *audio.Player
@@ -74,26 +65,42 @@ type Game struct {
 	customPlayerName   string
 	classicNameEntered bool
 	customNameEntered  bool
-	classicHighScore   HighScore
-	customHighScore    HighScore
+	leaderboards       map[string]*Leaderboard // таблицы рекордов по ключу режима и варианта
+	isRecorded         bool                    // результат закончившейся партии уже занесён в таблицу
+	newRecordPlace     int                     // место партии в таблице, -1 — в таблицу не попала
+	hasSavedGame       bool                    // на диске есть партия, сохранённая из меню паузы
+	savedGameNotice    string                  // почему сохранённую партию нельзя продолжить, пусто — сообщать нечего
+	profiles           map[string]Profile      // настройки игроков по имени
+	profile            string                  // имя текущего профиля, пустое — до ввода имени
 }
 
 func NewGame() (*Game, error) {
 	g := &Game{
-		fallSpeed:          speedLevels[0].fallSpeed,
 		images:             make(map[string]*ebiten.Image),
-		lastUpdate:         time.Now(),
-		keyLastAction:      make(map[ebiten.Key]time.Time),
-		keyPressStart:      make(map[ebiten.Key]time.Time),
+		bindings:           defaultBindings(),
+		previews:           5,
+		showGhost:          true,
+		ghostOpacity:       0.3,
+		handling:           engine.DefaultHandling,
 		state:              StateMenu,
 		lastState:          StateMenu,
 		classicPlayerName:  "",
 		customPlayerName:   "",
 		classicNameEntered: false,
 		customNameEntered:  false,
-		classicHighScore:   HighScore{Name: "", Score: 0},
-		customHighScore:    HighScore{Name: "", Score: 0},
-	}
+		ultraMinutes:       2,
+		marathonGoal:       marathonGoals[0],
+		digGoal:            digGoals[0],
+		messiness:          0.3,
+		fadeFrames:         fadeVariants[0],
+		leaderboards:       make(map[string]*Leaderboard),
+		newRecordPlace:     -1,
+		profiles:           make(map[string]Profile),
+	}
+	g.loadHighScores()
+	g.hasSavedGame = g.checkSavedGame()
+	g.loadProfiles()
+	g.selectProfile("")
 	g.settingsMenu = NewSettingsMenu(g)
 	err := g.loadAssets()
 	if err != nil {
@@ -105,8 +112,9 @@ func NewGame() (*Game, error) {
 	g.customMode = NewCustomMode(g)
 	g.enterName = NewEnterNameScreen(g)
 	g.highScoreScreen = NewHighScoreScreen(g)
-	g.currentPiece = g.newPiece()
-	g.nextPiece = g.newPiece()
+	g.replayBrowser = NewReplayBrowser(g)
+	g.replayViewer = NewReplayViewer(g)
+	g.engine = engine.New(g.config)
 	return g, nil
 }
 
@@ -133,7 +141,7 @@ func (g *Game) Update() error {
 				g.menuPlayer.Play()
 			}
 		case StateGame:
-			if g.isCustomSpeed {
+			if g.mode == ModeCustom {
 				if g.customPlayer != nil {
 					g.customPlayer.Rewind()
 					g.customPlayer.Play()
@@ -144,7 +152,7 @@ func (g *Game) Update() error {
 					g.gamePlayer.Play()
 				}
 			}
-		case StateCustomMode, StatePause, StateSettings, StateEnterName, StateHighScore:
+		case StateCustomMode, StatePause, StateSettings, StateEnterName, StateHighScore, StateReplays, StateReplay:
 			// Музыка не играет
 		}
 	}
@@ -197,42 +205,39 @@ func (g *Game) Update() error {
 		return nil
 	}
 
-	if g.isGameOver {
-		if g.isLimitedTo40Lines && g.score > g.classicHighScore.Score {
-			g.classicHighScore = HighScore{Name: g.classicPlayerName, Score: g.score}
-		} else if g.isCustomSpeed && g.score > g.customHighScore.Score {
-			g.customHighScore = HighScore{Name: g.customPlayerName, Score: g.score}
+	if g.state == StateReplays {
+		err := g.replayBrowser.Update()
+		if err != nil {
+			return err
+		}
+		return nil
+	}
+
+	if g.state == StateReplay {
+		err := g.replayViewer.Update()
+		if err != nil {
+			return err
+		}
+		return nil
+	}
+
+	if g.engine.IsOver() {
+		// Результат заносится в таблицу один раз, а не каждый кадр экрана окончания
+		if !g.isRecorded {
+			g.isRecorded = true
+			g.replayName = g.saveReplay()
+			g.newRecordPlace = g.recordHighScore()
+			if g.newRecordPlace >= 0 {
+				g.saveHighScores()
+			}
 		}
 
 		if inpututil.IsKeyJustPressed(ebiten.KeyR) {
-			g.grid = [gridHeight][gridWidth]string{}
-			g.currentPiece = g.newPiece()
-			g.nextPiece = g.newPiece()
-			g.score = 0
-			g.fallSpeed = speedLevels[0].fallSpeed
-			g.isGameOver = false
-			g.isPaused = false
-			g.isPieceGrounded = false
-			g.keyLastAction = make(map[ebiten.Key]time.Time)
-			g.keyPressStart = make(map[ebiten.Key]time.Time)
-			g.clearedLines = 0
+			g.restart()
 			g.state = StateGame
 		}
 		if inpututil.IsKeyJustPressed(ebiten.KeyQ) {
-			g.state = StateMenu
-			g.grid = [gridHeight][gridWidth]string{}
-			g.currentPiece = g.newPiece()
-			g.nextPiece = g.newPiece()
-			g.score = 0
-			g.fallSpeed = speedLevels[0].fallSpeed
-			g.isGameOver = false
-			g.isPaused = false
-			g.isPieceGrounded = false
-			g.keyLastAction = make(map[ebiten.Key]time.Time)
-			g.keyPressStart = make(map[ebiten.Key]time.Time)
-			g.clearedLines = 0
-			g.isCustomSpeed = false
-			g.isLimitedTo40Lines = false
+			g.quitToMenu()
 		}
 		return nil
 	}
@@ -242,20 +247,7 @@ func (g *Game) Update() error {
 			g.isPaused = true
 			g.state = StatePause
 		} else {
-			g.state = StateMenu
-			g.grid = [gridHeight][gridWidth]string{}
-			g.currentPiece = g.newPiece()
-			g.nextPiece = g.newPiece()
-			g.score = 0
-			g.fallSpeed = speedLevels[0].fallSpeed
-			g.isGameOver = false
-			g.isPaused = false
-			g.isPieceGrounded = false
-			g.keyLastAction = make(map[ebiten.Key]time.Time)
-			g.keyPressStart = make(map[ebiten.Key]time.Time)
-			g.clearedLines = 0
-			g.isCustomSpeed = false
-			g.isLimitedTo40Lines = false
+			g.quitToMenu()
 		}
 	}
 
@@ -263,133 +255,131 @@ func (g *Game) Update() error {
 		return nil
 	}
 
-	if inpututil.IsKeyJustPressed(ebiten.KeyLeft) {
-		g.movePiece(-1, 0)
-	}
-	if inpututil.IsKeyJustPressed(ebiten.KeyRight) {
-		g.movePiece(1, 0)
+	// Ebiten вызывает Update ровно TPS раз в секунду, поэтому один вызов — один кадр движка
+	g.engine.Step(g.readInput())
+	if g.lockLabelTimer > 0 {
+		g.lockLabelTimer--
 	}
-	if inpututil.IsKeyJustPressed(ebiten.KeyDown) {
-		g.movePiece(0, 1)
+	if g.flashTimer > 0 {
+		g.flashTimer--
 	}
 
-	keys := []ebiten.Key{ebiten.KeyLeft, ebiten.KeyRight, ebiten.KeyDown}
-	for _, key := range keys {
-		if ebiten.IsKeyPressed(key) {
-			now := time.Now()
-			if _, exists := g.keyPressStart[key]; !exists {
-				g.keyPressStart[key] = now
-			}
+	g.lastState = g.state
+	return nil
+}
 
-			if now.Sub(g.keyPressStart[key]) >= keyRepeatDelay {
-				lastAction, exists := g.keyLastAction[key]
-				if !exists || now.Sub(lastAction) >= keyRepeatInterval {
-					switch key {
-					case ebiten.KeyLeft:
-						g.movePiece(-1, 0)
-					case ebiten.KeyRight:
-						g.movePiece(1, 0)
-					case ebiten.KeyDown:
-						g.movePiece(0, 1)
-					}
-					g.keyLastAction[key] = now
-				}
-			}
-		} else {
-			delete(g.keyLastAction, key)
-			delete(g.keyPressStart, key)
+// readInput переводит состояние клавиатуры в кнопки движка
+func (g *Game) readInput() engine.Input {
+	var in engine.Input
+	for key, b := range g.bindings {
+		if ebiten.IsKeyPressed(key) {
+			in |= b
 		}
 	}
+	return in
+}
 
-	if inpututil.IsKeyJustPressed(ebiten.KeyZ) {
-		g.rotatePieceCounterClockwise()
-	}
-	if inpututil.IsKeyJustPressed(ebiten.KeyX) {
-		g.rotatePiece()
+// keyBindings — назначение клавиш кнопкам движка
+type keyBindings map[ebiten.Key]engine.Input
+
+// defaultBindings возвращает раскладку управления по умолчанию
+func defaultBindings() keyBindings {
+	return keyBindings{
+		ebiten.KeyLeft:       engine.InputLeft,
+		ebiten.KeyRight:      engine.InputRight,
+		ebiten.KeyDown:       engine.InputSoftDrop,
+		ebiten.KeySpace:      engine.InputHardDrop,
+		ebiten.KeyX:          engine.InputRotateCW,
+		ebiten.KeyZ:          engine.InputRotateCCW,
+		ebiten.KeyC:          engine.InputHold,
+		ebiten.KeyShiftLeft:  engine.InputHold,
+		ebiten.KeyShiftRight: engine.InputHold,
 	}
+}
 
-	if inpututil.IsKeyJustPressed(ebiten.KeySpace) {
-		for g.movePiece(0, 1) {
-		}
-		g.fixPiece()
-		g.clearLines()
-		g.currentPiece = g.nextPiece
-		g.nextPiece = g.newPiece()
-		g.isPieceGrounded = false
-		if g.checkCollision(g.currentPiece) {
-			g.isGameOver = true
-		}
-		g.lastUpdate = time.Now()
+// bind назначает клавишу действию, снимая с действия прежние клавиши.
+// Клавишу, занятую другим действием, не переназначает и возвращает false
+func (kb keyBindings) bind(key ebiten.Key, b engine.Input) bool {
+	if action, ok := kb[key]; ok && action != b {
+		return false
 	}
+	kb.unbind(b)
+	kb[key] = b
+	return true
+}
 
-	if time.Since(g.lastUpdate).Seconds() >= g.fallSpeed {
-		if !g.movePiece(0, 1) {
-			if !g.isPieceGrounded {
-				g.isPieceGrounded = true
-				g.lockDelayStart = time.Now()
-			}
-		} else {
-			g.isPieceGrounded = false
+// restore назначает действию сохранённые клавиши вместо прежних,
+// пропуская клавиши, занятые другими действиями
+func (kb keyBindings) restore(b engine.Input, keys []ebiten.Key) {
+	kb.unbind(b)
+	for _, key := range keys {
+		if _, ok := kb[key]; !ok {
+			kb[key] = b
 		}
-		g.lastUpdate = time.Now()
 	}
+}
 
-	if g.isPieceGrounded {
-		now := time.Now()
-		isMoving := ebiten.IsKeyPressed(ebiten.KeyLeft) || ebiten.IsKeyPressed(ebiten.KeyRight) || ebiten.IsKeyPressed(ebiten.KeyDown)
-		lockDelay := lockDelayDefault
-		if isMoving && now.Sub(g.lockDelayStart) < lockDelayLimit {
-			lockDelay = lockDelayLimit
-		}
-		if now.Sub(g.lockDelayStart) >= lockDelay {
-			g.fixPiece()
-			g.clearLines()
-			g.currentPiece = g.nextPiece
-			g.nextPiece = g.newPiece()
-			g.isPieceGrounded = false
-			if g.checkCollision(g.currentPiece) {
-				g.isGameOver = true
-			}
+// unbind снимает с действия все клавиши
+func (kb keyBindings) unbind(b engine.Input) {
+	for key, action := range kb {
+		if action == b {
+			delete(kb, key)
 		}
 	}
+}
 
-	if !g.isCustomSpeed {
-		scoreThreshold := g.score / 5000
-		if scoreThreshold >= len(speedLevels) {
-			scoreThreshold = len(speedLevels) - 1
+// keys возвращает клавиши, назначенные действию, по алфавиту
+func (kb keyBindings) keys(b engine.Input) []ebiten.Key {
+	var keys []ebiten.Key
+	for key, action := range kb {
+		if action == b {
+			keys = append(keys, key)
 		}
-		g.fallSpeed = speedLevels[scoreThreshold].fallSpeed
 	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+	return keys
+}
 
-	g.lastState = g.state
-	return nil
+// SetSeed фиксирует сид для всех последующих партий
+func (g *Game) SetSeed(seed uint64) {
+	g.seed = seed
+	g.isSeedFixed = true
+	g.customMode.seedInput = strconv.FormatUint(seed, 10)
 }
 
-func (g *Game) clearLines() {
-	linesCleared := 0
-	for i := gridHeight - 1; i >= 0; i-- {
-		filled := true
-		for j := 0; j < gridWidth; j++ {
-			if g.grid[i][j] == "" {
-				filled = false
-				break
-			}
-		}
-		if filled {
-			linesCleared++
-			for j := i; j > 0; j-- {
-				g.grid[j] = g.grid[j-1]
-			}
-			g.grid[0] = [gridWidth]string{}
-			i++
-		}
+// nextSeed возвращает сид новой партии. Сид из пользовательского режима
+// действует только в нём, а сид из командной строки — во всех режимах
+func (g *Game) nextSeed() uint64 {
+	switch {
+	case g.mode == ModeCustom && g.isCustomSeedFixed:
+		return g.customSeed
+	case g.isSeedFixed:
+		return g.seed
 	}
-	g.score += linesCleared * 100
-	g.clearedLines += linesCleared
+	return rand.Uint64()
+}
 
-	if g.isLimitedTo40Lines && g.clearedLines >= 40 {
-		g.isGameOver = true
-	}
+// restart начинает новую партию с текущими параметрами режима
+func (g *Game) restart() {
+	g.config.Seed = g.nextSeed()
+	g.config.Previews = g.previews
+	g.config.Handling = g.handling
+	g.engine = engine.New(g.config)
+	g.engine.Subscribe(g.onLock)
+	g.lockLabelTimer = 0
+	g.flashTimer = 0
+	g.isRecorded = false
+	g.newRecordPlace = -1
+	g.isPaused = false
+}
+
+// quitToMenu сбрасывает партию и возвращает в главное меню
+func (g *Game) quitToMenu() {
+	g.state = StateMenu
+	g.mode = ModeNone
+	g.variant = 0
+	g.config = engine.Config{}
+	g.restart()
 }
 
 func (g *Game) Draw(screen *ebiten.Image) {
@@ -421,7 +411,77 @@ func (g *Game) Draw(screen *ebiten.Image) {
 		g.highScoreScreen.Draw(screen)
 		return
 	}
+	if g.state == StateReplays {
+		g.replayBrowser.Draw(screen)
+		return
+	}
 
+	g.drawField(screen)
+	if g.state == StateReplay {
+		g.replayViewer.Draw(screen)
+		return
+	}
+
+	if g.engine.IsOver() {
+		overlay := ebiten.NewImage(ScreenWidth, ScreenHeight)
+		overlay.Fill(color.RGBA{20, 30, 50, 192})
+		op := &ebiten.DrawImageOptions{}
+		screen.DrawImage(overlay, op)
+
+		if g.font != nil {
+			title := "Вы проиграли!"
+			if g.mode == ModeUltra && g.engine.IsWon() {
+				title = "Время вышло!"
+			} else if g.engine.IsWon() {
+				title = "Вы выиграли!"
+			}
+			lines := []string{title}
+			if g.mode == ModeSurvival || (g.mode == ModeSprint || g.mode == ModeDig) && g.engine.IsWon() {
+				lines = append(lines, fmt.Sprintf("Время: %s", formatFrames(g.engine.Frames())))
+			} else {
+				lines = append(lines, fmt.Sprintf("Итоговый счёт: %d", g.engine.Score()))
+			}
+			lines = append(lines, fmt.Sprintf("Очищено линий: %d", g.engine.Lines()))
+			if g.mode == ModeMaster {
+				lines = append(lines, fmt.Sprintf("Звание: %s", g.engine.Grade()))
+			}
+			if g.mode == ModeDig || g.mode == ModeSurvival {
+				lines = append(lines, fmt.Sprintf("Мусорных линий: %d", g.engine.GarbageCleared()))
+			}
+			if g.mode == ModeSprint || g.mode == ModeUltra {
+				lines = append(lines,
+					fmt.Sprintf("Фигур: %d (%.2f в секунду)", g.engine.Pieces(), g.engine.PPS()),
+					fmt.Sprintf("Ошибки техники: %d", g.engine.FinesseFaults()),
+				)
+			}
+			if g.newRecordPlace >= 0 {
+				lines = append(lines, fmt.Sprintf("Новый рекорд: %d место", g.newRecordPlace+1))
+			}
+			lines = append(lines, fmt.Sprintf("Сид: %d", g.engine.Seed()), "R: Перезапустить", "Q: В меню")
+
+			// Центрирование текста по вертикали и горизонтали
+			top := ScreenHeight/2 - len(lines)*20
+			for i, line := range lines {
+				w, _ := text.Measure(line, g.font, 24)
+				drawText(screen, line, ScreenWidth/2-int(w/2), top+i*40, color.RGBA{180, 220, 255, 255}, g.font, false)
+			}
+		}
+	} else if !g.isPaused {
+		if g.font != nil {
+			// Центрирование текста "Счёт"
+			scoreText := fmt.Sprintf("Счёт: %d", g.engine.Score())
+			w, _ := text.Measure(scoreText, g.font, 24)
+			drawText(screen, scoreText, ScreenWidth/2-int(w/2), 30, color.RGBA{180, 220, 255, 255}, g.font, false)
+			// Центрирование текста "Для паузы"
+			pauseText := "Для паузы нажмите Esc"
+			w, _ = text.Measure(pauseText, g.font, 24)
+			drawText(screen, pauseText, ScreenWidth/2-int(w/2), ScreenHeight-30, color.RGBA{180, 220, 255, 255}, g.font, false)
+		}
+	}
+}
+
+// drawField отрисовывает поле партии с тенью, запасом, сведениями и очередью
+func (g *Game) drawField(screen *ebiten.Image) {
 	offsetX := (ScreenWidth - gridWidth*cellSize) / 2
 	offsetY := (ScreenHeight - gridHeight*cellSize) / 2
 
@@ -432,79 +492,119 @@ func (g *Game) Draw(screen *ebiten.Image) {
 	borderOp.GeoM.Translate(float64(offsetX-2), float64(offsetY-2))
 	screen.DrawImage(border, borderOp)
 
-	// Отрисовка игрового поля
-	for i := 0; i < gridHeight; i++ {
-		for j := 0; j < gridWidth; j++ {
-			op := &ebiten.DrawImageOptions{}
-			op.GeoM.Translate(float64(j*cellSize+offsetX), float64(i*cellSize+offsetY))
-			if g.grid[i][j] != "" {
-				screen.DrawImage(g.images[g.grid[i][j]], op)
-			} else {
-				screen.DrawImage(g.images["boardcell"], op)
+	// Отрисовка игрового поля. Стек невидимки открывается только после конца партии
+	board := g.engine.Board()
+	if g.mode == ModeInvisible && !g.engine.IsOver() {
+		g.drawFadingBoard(screen, offsetX, offsetY)
+	} else {
+		for i := 0; i < gridHeight; i++ {
+			for j := 0; j < gridWidth; j++ {
+				op := &ebiten.DrawImageOptions{}
+				op.GeoM.Translate(float64(j*cellSize+offsetX), float64(i*cellSize+offsetY))
+				if board[i][j] != "" {
+					screen.DrawImage(g.images[board[i][j]], op)
+				} else {
+					screen.DrawImage(g.images["boardcell"], op)
+				}
 			}
 		}
 	}
 
-	// Отрисовка текущей фигуры
-	for i, row := range g.currentPiece.shape {
-		for j, cell := range row {
-			if cell != 0 {
-				op := &ebiten.DrawImageOptions{}
-				op.GeoM.Translate(float64((g.currentPiece.x+j)*cellSize+offsetX), float64((g.currentPiece.y+i)*cellSize+offsetY))
-				screen.DrawImage(g.currentPiece.image, op)
+	// Отрисовка тени в месте, куда упадёт фигура при жёстком сбросе
+	if g.showGhost && !g.engine.IsOver() && !g.engine.InEntryDelay() {
+		ghost := g.engine.Ghost()
+		for i, row := range ghost.Shape() {
+			for j, cell := range row {
+				if cell != 0 && ghost.Y+i >= 0 {
+					op := &ebiten.DrawImageOptions{}
+					op.GeoM.Translate(float64((ghost.X+j)*cellSize+offsetX), float64((ghost.Y+i)*cellSize+offsetY))
+					op.ColorScale.ScaleAlpha(g.ghostOpacity)
+					screen.DrawImage(g.images[ghost.Kind], op)
+				}
 			}
 		}
 	}
 
-	if g.isGameOver {
-		overlay := ebiten.NewImage(ScreenWidth, ScreenHeight)
-		overlay.Fill(color.RGBA{20, 30, 50, 192})
-		op := &ebiten.DrawImageOptions{}
-		screen.DrawImage(overlay, op)
-
-		if g.isLimitedTo40Lines && g.clearedLines >= 40 {
-			if g.font != nil {
-				// Центрирование текста
-				winText := "Вы выиграли!"
-				w, _ := text.Measure(winText, g.font, 24)
-				drawText(screen, winText, ScreenWidth/2-int(w/2), ScreenHeight/2-100, color.RGBA{180, 220, 255, 255}, g.font, false)
-			}
-		} else {
-			if g.font != nil {
-				// Центрирование текста
-				loseText := "Вы проиграли!"
-				w, _ := text.Measure(loseText, g.font, 24)
-				drawText(screen, loseText, ScreenWidth/2-int(w/2), ScreenHeight/2-100, color.RGBA{180, 220, 255, 255}, g.font, false)
+	// Отрисовка текущей фигуры. Во время ARE фигуры на поле нет
+	if !g.engine.InEntryDelay() {
+		piece := g.engine.Current()
+		for i, row := range piece.Shape() {
+			for j, cell := range row {
+				if cell != 0 && piece.Y+i >= 0 {
+					op := &ebiten.DrawImageOptions{}
+					op.GeoM.Translate(float64((piece.X+j)*cellSize+offsetX), float64((piece.Y+i)*cellSize+offsetY))
+					screen.DrawImage(g.images[piece.Kind], op)
+				}
 			}
 		}
-		if g.font != nil {
-			// Центрирование текста
-			scoreText := fmt.Sprintf("Итоговый счёт: %d", g.score)
-			w, _ := text.Measure(scoreText, g.font, 24)
-			drawText(screen, scoreText, ScreenWidth/2-int(w/2), ScreenHeight/2-60, color.RGBA{180, 220, 255, 255}, g.font, false)
+	}
+
+	// Отрисовка запаса слева от поля
+	g.drawPanel(screen, "Запас", offsetX-cellSize*5, offsetY, 5)
+	if hold := g.engine.Hold(); hold != "" {
+		alpha := float32(1)
+		if !g.engine.CanHold() {
+			alpha = 0.4
+		}
+		g.drawPiecePreview(screen, hold, offsetX-cellSize*5+cellSize/2, offsetY+cellSize*3/2, alpha)
+	}
+
+	g.drawHUD(screen, offsetX-cellSize*5, offsetY+cellSize*6)
+
+	// Отрисовка очереди справа от поля
+	queueX := offsetX + gridWidth*cellSize + cellSize
+	g.drawPanel(screen, "Далее", queueX, offsetY, 2+g.config.Previews*3)
+	for i, kind := range g.engine.Queue() {
+		slotY := offsetY + cellSize*3/2 + i*previewSize
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Translate(float64(queueX+cellSize/2), float64(slotY))
+		screen.DrawImage(g.images["nextblock"], op)
+		g.drawPiecePreview(screen, kind, queueX+cellSize/2, slotY, 1)
+	}
+}
 
-			linesText := fmt.Sprintf("Очищено линий: %d", g.clearedLines)
-			w, _ = text.Measure(linesText, g.font, 24)
-			drawText(screen, linesText, ScreenWidth/2-int(w/2), ScreenHeight/2-20, color.RGBA{180, 220, 255, 255}, g.font, false)
+// drawPanel отрисовывает боковую панель шириной 4 клетки с заголовком
+func (g *Game) drawPanel(screen *ebiten.Image, title string, x, y, rows int) {
+	panel := ebiten.NewImage(cellSize*4+cellSize/2, cellSize*rows)
+	panel.Fill(color.RGBA{30, 45, 70, 255})
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(float64(x-cellSize/4), float64(y))
+	screen.DrawImage(panel, op)
+	if g.font != nil {
+		drawText(screen, title, x, y, color.RGBA{180, 220, 255, 255}, g.font, false)
+	}
+}
 
-			restartText := "R: Перезапустить"
-			w, _ = text.Measure(restartText, g.font, 24)
-			drawText(screen, restartText, ScreenWidth/2-int(w/2), ScreenHeight/2+20, color.RGBA{180, 220, 255, 255}, g.font, false)
+// drawPiecePreview отрисовывает фигуру в положении появления в уменьшенном масштабе,
+// выравнивая её по центру квадрата previewSize с левым верхним углом в (x, y)
+func (g *Game) drawPiecePreview(screen *ebiten.Image, kind string, x, y int, alpha float32) {
+	const scale = 0.75
+	piece := engine.Piece{Kind: kind}
+	shape := piece.Shape()
 
-			menuText := "Q: В меню"
-			w, _ = text.Measure(menuText, g.font, 24)
-			drawText(screen, menuText, ScreenWidth/2-int(w/2), ScreenHeight/2+60, color.RGBA{180, 220, 255, 255}, g.font, false)
+	// Границы занятых клеток, чтобы пустые строки и столбцы матрицы не сдвигали фигуру
+	minI, maxI, minJ, maxJ := len(shape), -1, len(shape), -1
+	for i, row := range shape {
+		for j, cell := range row {
+			if cell != 0 {
+				minI, maxI = min(minI, i), max(maxI, i)
+				minJ, maxJ = min(minJ, j), max(maxJ, j)
+			}
 		}
-	} else if !g.isPaused {
-		if g.font != nil {
-			// Центрирование текста "Счёт"
-			scoreText := fmt.Sprintf("Счёт: %d", g.score)
-			w, _ := text.Measure(scoreText, g.font, 24)
-			drawText(screen, scoreText, ScreenWidth/2-int(w/2), 30, color.RGBA{180, 220, 255, 255}, g.font, false)
-			// Центрирование текста "Для паузы"
-			pauseText := "Для паузы нажмите Esc"
-			w, _ = text.Measure(pauseText, g.font, 24)
-			drawText(screen, pauseText, ScreenWidth/2-int(w/2), ScreenHeight-30, color.RGBA{180, 220, 255, 255}, g.font, false)
+	}
+	size := float64(cellSize) * scale
+	left := float64(x) + (previewSize-float64(maxJ-minJ+1)*size)/2
+	top := float64(y) + (previewSize-float64(maxI-minI+1)*size)/2
+
+	for i, row := range shape {
+		for j, cell := range row {
+			if cell != 0 {
+				op := &ebiten.DrawImageOptions{}
+				op.GeoM.Scale(scale, scale)
+				op.GeoM.Translate(left+float64(j-minJ)*size, top+float64(i-minI)*size)
+				op.ColorScale.ScaleAlpha(alpha)
+				screen.DrawImage(g.images[kind], op)
+			}
 		}
 	}
 }
@@ -512,26 +612,3 @@ func (g *Game) Draw(screen *ebiten.Image) {
 func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
 	return ScreenWidth, ScreenHeight
 }
-
-func (g *Game) start40Lines() {
-	if !g.classicNameEntered {
-		g.state = StateEnterName
-		g.enterName.nextState = StateGame
-		g.enterName.isCustomMode = false
-		return
-	}
-	g.grid = [gridHeight][gridWidth]string{}
-	g.currentPiece = g.newPiece()
-	g.nextPiece = g.newPiece()
-	g.score = 0
-	g.fallSpeed = speedLevels[0].fallSpeed
-	g.isGameOver = false
-	g.isPaused = false
-	g.isPieceGrounded = false
-	g.keyLastAction = make(map[ebiten.Key]time.Time)
-	g.keyPressStart = make(map[ebiten.Key]time.Time)
-	g.isLimitedTo40Lines = true
-	g.isCustomSpeed = false
-	g.clearedLines = 0
-	g.state = StateGame
-}
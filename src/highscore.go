@@ -6,22 +6,41 @@ import (
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/hajimehoshi/ebiten/v2/text/v2"
 	"image/color"
+	"slices"
 )
 
-// HighScoreScreen представляет экран рекордов
+// HighScoreScreen представляет экран рекордов. Каждая страница — таблица одного режима
 type HighScoreScreen struct {
-	game *Game
+	game           *Game
+	page           int
+	highlightKey   string // таблица с последним новым рекордом
+	highlightIndex int    // место нового рекорда в таблице highlightKey
 }
 
 // NewHighScoreScreen создает новый экран рекордов
 func NewHighScoreScreen(game *Game) *HighScoreScreen {
 	return &HighScoreScreen{
-		game: game,
+		game:           game,
+		highlightIndex: -1,
 	}
 }
 
+// highlight выделяет новую запись и открывает её таблицу при следующем показе экрана
+func (hs *HighScoreScreen) highlight(key string, index int) {
+	hs.highlightKey = key
+	hs.highlightIndex = index
+	hs.page = slices.IndexFunc(hs.game.leaderboardPages(), func(info boardInfo) bool { return info.key == key })
+}
+
 // Update обновляет экран рекордов
 func (hs *HighScoreScreen) Update() error {
+	pages := hs.game.leaderboardPages()
+	if inpututil.IsKeyJustPressed(ebiten.KeyArrowLeft) {
+		hs.page = (hs.page + len(pages) - 1) % len(pages)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyArrowRight) {
+		hs.page = (hs.page + 1) % len(pages)
+	}
 	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) || inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
 		hs.game.state = StateMenu
 	}
@@ -34,37 +53,60 @@ func (hs *HighScoreScreen) Draw(screen *ebiten.Image) {
 	overlay.Fill(color.RGBA{20, 30, 50, 192})
 	screen.DrawImage(overlay, &ebiten.DrawImageOptions{})
 
-	if hs.game.font != nil {
-		// Центрирование заголовка "Рекорды"
-		headerText := "Рекорды"
-		w, _ := text.Measure(headerText, hs.game.font, 32)
-		drawText(screen, headerText, ScreenWidth/2-int(w/2), ScreenHeight/2-150, color.RGBA{180, 220, 255, 255}, hs.game.font, false)
+	if hs.game.font == nil {
+		return
+	}
 
-		classicName := hs.game.classicHighScore.Name
-		if classicName == "" {
-			classicName = "–"
-		}
-		customName := hs.game.customHighScore.Name
-		if customName == "" {
-			customName = "–"
-		}
+	pages := hs.game.leaderboardPages()
+	hs.page = max(min(hs.page, len(pages)-1), 0)
+	info := pages[hs.page]
+	key := info.key
+	lb, ok := hs.game.leaderboards[key]
+	if !ok {
+		// Таблица режима, в котором ещё никто не играл, показывается пустой
+		lb = &Leaderboard{Title: info.title, Ranking: info.ranking}
+	}
 
-		// Центрирование текста
-		line1 := "40 линий:"
-		w, _ = text.Measure(line1, hs.game.font, 24)
-		drawText(screen, line1, ScreenWidth/2-int(w/2), ScreenHeight/2-50, color.RGBA{180, 220, 255, 255}, hs.game.font, false)
+	// Центрирование заголовка с названием таблицы
+	headerText := "Рекорды: " + lb.Title
+	w, _ := text.Measure(headerText, hs.game.font, 32)
+	drawText(screen, headerText, ScreenWidth/2-int(w/2), 40, color.RGBA{180, 220, 255, 255}, hs.game.font, false)
 
-		line2 := fmt.Sprintf("%s: %d", classicName, hs.game.classicHighScore.Score)
-		w, _ = text.Measure(line2, hs.game.font, 24)
-		drawText(screen, line2, ScreenWidth/2-int(w/2), ScreenHeight/2-20, color.RGBA{180, 220, 255, 255}, hs.game.font, false)
+	pageText := fmt.Sprintf("< %d / %d >", hs.page+1, len(pages))
+	w, _ = text.Measure(pageText, hs.game.font, 24)
+	drawText(screen, pageText, ScreenWidth/2-int(w/2), 80, color.RGBA{180, 220, 255, 255}, hs.game.font, false)
 
-		line3 := "Пользовательский:"
-		w, _ = text.Measure(line3, hs.game.font, 24)
-		drawText(screen, line3, ScreenWidth/2-int(w/2), ScreenHeight/2+20, color.RGBA{180, 220, 255, 255}, hs.game.font, false)
+	if len(lb.Entries) == 0 {
+		emptyText := "Рекордов пока нет"
+		w, _ = text.Measure(emptyText, hs.game.font, 24)
+		drawText(screen, emptyText, ScreenWidth/2-int(w/2), ScreenHeight/2-20, color.RGBA{180, 220, 255, 255}, hs.game.font, false)
+	}
+	for i, entry := range lb.Entries {
+		var clr color.Color = color.RGBA{180, 220, 255, 255}
+		isNew := key == hs.highlightKey && i == hs.highlightIndex
+		if isNew {
+			clr = color.RGBA{255, 220, 120, 255}
+		}
+		line := fmt.Sprintf("%d. %s — %s, %s", i+1, recordName(entry), lb.result(entry), entry.Date.Format("02.01.2006"))
+		drawText(screen, line, 40, 130+i*40, clr, hs.game.font, isNew)
+	}
+}
 
-		line4 := fmt.Sprintf("%s: %d", customName, hs.game.customHighScore.Score)
-		w, _ = text.Measure(line4, hs.game.font, 24)
-		drawText(screen, line4, ScreenWidth/2-int(w/2), ScreenHeight/2+50, color.RGBA{180, 220, 255, 255}, hs.game.font, false)
+// result возвращает результат записи в единицах, по которым ведётся таблица
+func (lb *Leaderboard) result(entry HighScore) string {
+	switch {
+	case lb.Ranking != RankScore:
+		return formatFrames(entry.Frames)
+	case entry.Grade != "":
+		return fmt.Sprintf("%s (%d)", entry.Grade, entry.Score)
+	}
+	return fmt.Sprintf("%d", entry.Score)
+}
 
+// recordName возвращает имя владельца рекорда или прочерк, если имя не задано
+func recordName(hs HighScore) string {
+	if hs.Name == "" {
+		return "–"
 	}
+	return hs.Name
 }
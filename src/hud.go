@@ -0,0 +1,95 @@
+package src
+
+import (
+	"fmt"
+	"github.com/Xu3is/Zetris/src/engine"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"image/color"
+	"strings"
+)
+
+// lockLabelFrames — сколько кадров показывается подпись последней фиксации
+const lockLabelFrames = 90
+
+// clearNames содержит названия очисток по количеству линий
+var clearNames = []string{"", "Single", "Double", "Triple", "Tetris"}
+
+// onLock запоминает подпись к фиксации, если она заслуживает внимания,
+// и подсвечивает невидимый стек при очистке линий
+func (g *Game) onLock(r engine.LockResult) {
+	if r.Lines > 0 && g.mode == ModeInvisible {
+		g.flashTimer = flashFrames
+	}
+	label := lockLabel(r)
+	if label != "" {
+		g.lockLabel = label
+		g.lockLabelTimer = lockLabelFrames
+	}
+}
+
+// lockLabel возвращает подпись к фиксации по строкам, например "T-Spin Mini\nDouble".
+// Для фиксаций без очистки и T-spin подпись пустая
+func lockLabel(r engine.LockResult) string {
+	var lines []string
+	switch r.TSpin {
+	case engine.TSpinFull:
+		lines = append(lines, "T-Spin")
+	case engine.TSpinMini:
+		lines = append(lines, "T-Spin Mini")
+	}
+	if r.Lines > 0 {
+		lines = append(lines, clearNames[r.Lines])
+	}
+	if r.BackToBack {
+		lines = append(lines, "Back-to-Back")
+	}
+	if r.PerfectClear {
+		lines = append(lines, "Perfect Clear")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// drawHUD отрисовывает сведения о партии в колонке слева от поля
+func (g *Game) drawHUD(screen *ebiten.Image, x, y int) {
+	if g.font == nil {
+		return
+	}
+	smallFont := &text.GoTextFace{
+		Source: g.font.Source,
+		Size:   18,
+	}
+	if g.lockLabelTimer > 0 {
+		drawText(screen, g.lockLabel, x, y, color.RGBA{255, 220, 120, 255}, smallFont, false)
+	}
+	clock := formatFrames(g.engine.Frames())
+	if g.mode == ModeUltra {
+		// В Ultra часы идут в обратную сторону
+		clock = formatFrames(g.engine.TimeLeft())
+	}
+	drawText(screen, clock, x, y+cellSize*7, color.RGBA{180, 220, 255, 255}, smallFont, false)
+	drawText(screen, fmt.Sprintf("Уровень: %d", g.engine.Level()), x, y+cellSize*8, color.RGBA{180, 220, 255, 255}, smallFont, false)
+	lines := fmt.Sprintf("Линии: %d", g.engine.Lines())
+	if g.config.LineGoal > 0 {
+		lines = fmt.Sprintf("Линии: %d/%d", g.engine.Lines(), g.config.LineGoal)
+	}
+	drawText(screen, lines, x, y+cellSize*9, color.RGBA{180, 220, 255, 255}, smallFont, false)
+	if g.mode == ModeMaster {
+		drawText(screen, fmt.Sprintf("Звание: %s", g.engine.Grade()), x, y+cellSize*10, color.RGBA{180, 220, 255, 255}, smallFont, false)
+	}
+	if g.mode == ModeDig {
+		drawText(screen, fmt.Sprintf("Мусор: %d/%d", g.engine.GarbageCleared(), g.config.DigGoal), x, y+cellSize*10, color.RGBA{180, 220, 255, 255}, smallFont, false)
+	}
+	if combo := g.engine.Combo(); combo > 0 {
+		drawText(screen, fmt.Sprintf("Комбо: %d", combo), x, y+cellSize*5, color.RGBA{180, 220, 255, 255}, smallFont, false)
+	}
+	if b2b := g.engine.BackToBack(); b2b > 0 {
+		drawText(screen, fmt.Sprintf("B2B x%d", b2b), x, y+cellSize*6, color.RGBA{180, 220, 255, 255}, smallFont, false)
+	}
+}
+
+// formatFrames переводит число кадров во время вида 1:23.456
+func formatFrames(frames int) string {
+	ms := engine.FramesToDuration(frames).Milliseconds()
+	return fmt.Sprintf("%d:%02d.%03d", ms/60000, ms/1000%60, ms%1000)
+}
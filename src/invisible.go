@@ -0,0 +1,56 @@
+package src
+
+import (
+	"fmt"
+	"github.com/Xu3is/Zetris/src/engine"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// fadeVariants перечисляет варианты невидимки: через сколько кадров исчезают клетки, 0 — сразу
+var fadeVariants = []int{0, 3 * engine.TPS, 5 * engine.TPS, 10 * engine.TPS}
+
+const (
+	fadeOutFrames = 30 // длительность угасания клетки
+	flashFrames   = 20 // длительность вспышки стека при очистке линий
+)
+
+// fadeName возвращает название варианта невидимки
+func fadeName(frames int) string {
+	if frames == 0 {
+		return "сразу"
+	}
+	return fmt.Sprintf("%d с", frames/engine.TPS)
+}
+
+// cellAlpha возвращает непрозрачность зафиксированной клетки в режиме невидимки
+func (g *Game) cellAlpha(x, y int) float32 {
+	flash := float32(g.flashTimer) / flashFrames
+	age := g.engine.Frames() - g.engine.LockedAt(x, y) - g.variant
+	switch {
+	case g.variant == 0 || age >= fadeOutFrames:
+		return flash
+	case age <= 0:
+		return 1
+	}
+	return max(1-float32(age)/fadeOutFrames, flash)
+}
+
+// drawFadingBoard отрисовывает поле невидимки: клетки стека постепенно исчезают,
+// а при очистке линий весь стек ненадолго вспыхивает
+func (g *Game) drawFadingBoard(screen *ebiten.Image, offsetX, offsetY int) {
+	board := g.engine.Board()
+	for i := 0; i < gridHeight; i++ {
+		for j := 0; j < gridWidth; j++ {
+			op := &ebiten.DrawImageOptions{}
+			op.GeoM.Translate(float64(j*cellSize+offsetX), float64(i*cellSize+offsetY))
+			screen.DrawImage(g.images["boardcell"], op)
+			if board[i][j] == "" {
+				continue
+			}
+			if alpha := g.cellAlpha(j, i); alpha > 0 {
+				op.ColorScale.ScaleAlpha(alpha)
+				screen.DrawImage(g.images[board[i][j]], op)
+			}
+		}
+	}
+}
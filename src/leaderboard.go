@@ -0,0 +1,221 @@
+package src
+
+import (
+	"fmt"
+	"github.com/Xu3is/Zetris/src/engine"
+	"maps"
+	"slices"
+	"time"
+)
+
+// leaderboardSize — сколько лучших результатов хранится в каждой таблице
+const leaderboardSize = 10
+
+// HighScore — запись таблицы рекордов
+type HighScore struct {
+	Name          string    `json:"name"`
+	Score         int       `json:"score"`
+	Frames        int       `json:"frames"` // длительность партии в кадрах
+	Lines         int       `json:"lines"`
+	Level         int       `json:"level"`
+	Pieces        int       `json:"pieces"`
+	FinesseFaults int       `json:"finesseFaults"`
+	Grade         string    `json:"grade,omitempty"` // звание TGM в режиме мастера
+	Date          time.Time `json:"date"`
+	Seed          uint64    `json:"seed"`
+	Replay        string    `json:"replay,omitempty"` // имя файла записи партии, пусто — записи нет
+}
+
+// Ranking определяет, какой результат в таблице считается лучшим
+type Ranking int
+
+const (
+	RankScore   Ranking = iota // больше очков
+	RankFastest                // меньше времени
+	RankLongest                // больше времени
+)
+
+// Leaderboard — таблица лучших результатов одного режима и варианта, от лучшего к худшему
+type Leaderboard struct {
+	Title   string      `json:"title"`
+	Ranking Ranking     `json:"ranking"`
+	Entries []HighScore `json:"entries"`
+}
+
+// better сообщает, лучше ли результат a, чем b
+func (lb *Leaderboard) better(a, b HighScore) bool {
+	switch lb.Ranking {
+	case RankFastest:
+		return a.Frames < b.Frames
+	case RankLongest:
+		return a.Frames > b.Frames
+	}
+	return a.Score > b.Score
+}
+
+// insert заносит результат в таблицу. Возвращает занятое место, начиная с 0,
+// или -1, если результат не вошёл в число лучших. При равенстве выше стоит более ранний
+func (lb *Leaderboard) insert(entry HighScore) int {
+	place := len(lb.Entries)
+	for i, e := range lb.Entries {
+		if lb.better(entry, e) {
+			place = i
+			break
+		}
+	}
+	if place >= leaderboardSize {
+		return -1
+	}
+	lb.Entries = slices.Insert(lb.Entries, place, entry)
+	if len(lb.Entries) > leaderboardSize {
+		lb.Entries = lb.Entries[:leaderboardSize]
+	}
+	return place
+}
+
+// boardInfo описывает таблицу рекордов режима до того, как в ней появились записи
+type boardInfo struct {
+	key     string
+	title   string
+	ranking Ranking
+}
+
+// sprintBoard описывает таблицу спринта на 40 линий
+func sprintBoard() boardInfo {
+	return boardInfo{"sprint", "40 линий", RankFastest}
+}
+
+// ultraBoard описывает таблицу Ultra заданной длительности
+func ultraBoard(minutes int) boardInfo {
+	return boardInfo{fmt.Sprintf("ultra/%d", minutes), fmt.Sprintf("Ultra %d мин", minutes), RankScore}
+}
+
+// marathonBoard описывает таблицу варианта марафона
+func marathonBoard(goal int) boardInfo {
+	return boardInfo{fmt.Sprintf("marathon/%d", goal), "Марафон: " + marathonName(goal), RankScore}
+}
+
+// digBoard описывает таблицу варианта копания
+func digBoard(goal int) boardInfo {
+	return boardInfo{fmt.Sprintf("dig/%d", goal), fmt.Sprintf("Копание %d линий", goal), RankFastest}
+}
+
+// survivalBoard описывает таблицу выживания
+func survivalBoard() boardInfo {
+	return boardInfo{"survival", "Выживание", RankLongest}
+}
+
+// masterBoard описывает таблицу режима мастера
+func masterBoard() boardInfo {
+	return boardInfo{"master", "Мастер", RankScore}
+}
+
+// invisibleBoard описывает таблицу варианта невидимки
+func invisibleBoard(fade int) boardInfo {
+	return boardInfo{fmt.Sprintf("invisible/%d", fade), "Невидимка: " + fadeName(fade), RankScore}
+}
+
+// customBoard описывает таблицу пользовательского режима: отдельную для каждых правил и скорости
+func customBoard(config engine.Config) boardInfo {
+	return boardInfo{
+		fmt.Sprintf("custom/%s/%d", config.Ruleset, config.Level),
+		fmt.Sprintf("Пользовательский: %s, скорость %d", config.Ruleset, config.Level),
+		RankScore,
+	}
+}
+
+// modeBoard возвращает таблицу рекордов режима mode с вариантом variant
+// и параметрами партии config
+func modeBoard(mode Mode, variant int, config engine.Config) (boardInfo, bool) {
+	switch mode {
+	case ModeSprint:
+		return sprintBoard(), true
+	case ModeUltra:
+		return ultraBoard(variant), true
+	case ModeMarathon:
+		return marathonBoard(variant), true
+	case ModeDig:
+		return digBoard(variant), true
+	case ModeSurvival:
+		return survivalBoard(), true
+	case ModeMaster:
+		return masterBoard(), true
+	case ModeInvisible:
+		return invisibleBoard(variant), true
+	case ModeCustom:
+		return customBoard(config), true
+	}
+	return boardInfo{}, false
+}
+
+// currentBoard возвращает таблицу рекордов текущего режима
+func (g *Game) currentBoard() (boardInfo, bool) {
+	return modeBoard(g.mode, g.variant, g.config)
+}
+
+// leaderboardPages возвращает таблицы в порядке страниц экрана рекордов:
+// сначала таблицы режимов из меню, в том числе ещё пустые, затем остальные
+// сохранённые по алфавиту. Сами таблицы при этом не создаются
+func (g *Game) leaderboardPages() []boardInfo {
+	pages := []boardInfo{sprintBoard(), ultraBoard(g.ultraMinutes)}
+	for _, goal := range marathonGoals {
+		pages = append(pages, marathonBoard(goal))
+	}
+	for _, goal := range digGoals {
+		pages = append(pages, digBoard(goal))
+	}
+	pages = append(pages, survivalBoard(), masterBoard())
+	for _, fade := range fadeVariants {
+		pages = append(pages, invisibleBoard(fade))
+	}
+	for _, key := range slices.Sorted(maps.Keys(g.leaderboards)) {
+		if !slices.ContainsFunc(pages, func(info boardInfo) bool { return info.key == key }) {
+			lb := g.leaderboards[key]
+			pages = append(pages, boardInfo{key, lb.Title, lb.Ranking})
+		}
+	}
+	return pages
+}
+
+// leaderboard возвращает таблицу рекордов, создавая пустую при первом обращении
+func (g *Game) leaderboard(info boardInfo) *Leaderboard {
+	lb, ok := g.leaderboards[info.key]
+	if !ok {
+		lb = &Leaderboard{Title: info.title, Ranking: info.ranking}
+		g.leaderboards[info.key] = lb
+	}
+	return lb
+}
+
+// recordHighScore заносит закончившуюся партию в таблицу её режима и отмечает
+// новую запись на экране рекордов. Возвращает место, начиная с 0, или -1
+func (g *Game) recordHighScore() int {
+	info, ok := g.currentBoard()
+	if !ok {
+		return -1
+	}
+	// В спринте, Ultra и копании засчитываются только партии, дошедшие до цели
+	if (g.mode == ModeSprint || g.mode == ModeUltra || g.mode == ModeDig) && !g.engine.IsWon() {
+		return -1
+	}
+	entry := HighScore{
+		Name:          g.playerName(),
+		Score:         g.engine.Score(),
+		Frames:        g.engine.Frames(),
+		Lines:         g.engine.Lines(),
+		Level:         g.engine.Level(),
+		Pieces:        g.engine.Pieces(),
+		FinesseFaults: g.engine.FinesseFaults(),
+		Date:          time.Now(),
+		Seed:          g.engine.Seed(),
+		Replay:        g.replayName,
+	}
+	if g.mode == ModeMaster {
+		entry.Grade = g.engine.Grade()
+	}
+	place := g.leaderboard(info).insert(entry)
+	if place >= 0 {
+		g.highScoreScreen.highlight(info.key, place)
+	}
+	return place
+}
@@ -1,12 +1,15 @@
 package src
 
 import (
+	"fmt"
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
 	"image/color"
 	"log"
 	"os"
+	"slices"
 )
 
 // GameState определяет текущее состояние игры
@@ -20,6 +23,8 @@ const (
 	StateSettings
 	StateEnterName
 	StateHighScore
+	StateReplays
+	StateReplay
 )
 
 // Menu представляет главное меню игры
@@ -34,9 +39,9 @@ type Menu struct {
 func NewMenu(game *Game) *Menu {
 	m := &Menu{
 		game:          game,
-		buttons:       []string{"40 линий", "Пользовательский", "Рекорды", "Настройки", "Выход"},
 		selectedIndex: 0,
 	}
+	m.refreshButtons()
 
 	// Загрузка изображения логотипа
 	path := "src/assets/images/zetris.png"
@@ -56,8 +61,21 @@ func NewMenu(game *Game) *Menu {
 	return m
 }
 
+// menuButtons — пункты главного меню, кроме «Продолжить»
+var menuButtons = []string{"40 линий", "Ultra", "Марафон", "Копание", "Выживание", "Мастер", "Невидимка", "Пользовательский", "Рекорды", "Записи", "Настройки", "Выход"}
+
+// refreshButtons показывает «Продолжить» первым пунктом, пока есть сохранённая партия
+func (m *Menu) refreshButtons() {
+	m.buttons = menuButtons
+	if m.game.hasSavedGame {
+		m.buttons = append([]string{"Продолжить"}, menuButtons...)
+	}
+	m.selectedIndex = min(m.selectedIndex, len(m.buttons)-1)
+}
+
 // Update обновляет состояние главного меню
 func (m *Menu) Update() error {
+	m.refreshButtons()
 	if inpututil.IsKeyJustPressed(ebiten.KeyArrowUp) {
 		m.selectedIndex--
 		if m.selectedIndex < 0 {
@@ -71,16 +89,43 @@ func (m *Menu) Update() error {
 		}
 	}
 
+	// Варианты режимов выбираются стрелками прямо на кнопке
+	switch m.buttons[m.selectedIndex] {
+	case "Марафон":
+		cycleVariant(&m.game.marathonGoal, marathonGoals)
+	case "Копание":
+		cycleVariant(&m.game.digGoal, digGoals)
+	case "Невидимка":
+		cycleVariant(&m.game.fadeFrames, fadeVariants)
+	}
+
 	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
+		m.game.savedGameNotice = ""
 		switch m.buttons[m.selectedIndex] {
+		case "Продолжить":
+			m.game.continueGame()
 		case "40 линий":
-			m.game.start40Lines()
+			m.game.startMode(ModeSprint)
+		case "Ultra":
+			m.game.startMode(ModeUltra)
+		case "Марафон":
+			m.game.startMode(ModeMarathon)
+		case "Копание":
+			m.game.startMode(ModeDig)
+		case "Выживание":
+			m.game.startMode(ModeSurvival)
+		case "Мастер":
+			m.game.startMode(ModeMaster)
+		case "Невидимка":
+			m.game.startMode(ModeInvisible)
 		case "Пользовательский":
 			m.game.state = StateCustomMode
 		case "Рекорды":
 			m.game.state = StateHighScore
+		case "Записи":
+			m.game.replayBrowser.open()
 		case "Настройки":
-			m.game.state = StateSettings
+			m.game.settingsMenu.open()
 		case "Выход":
 			os.Exit(0)
 		}
@@ -106,13 +151,44 @@ func (m *Menu) Draw(screen *ebiten.Image) {
 	}
 
 	for i, button := range m.buttons {
-		y := ScreenHeight/2 - 50 + i*40
+		y := ScreenHeight/2 - 110 + i*30
 		var clr color.Color = color.RGBA{180, 220, 255, 255}
 		if i == m.selectedIndex {
 			clr = color.RGBA{100, 200, 255, 255}
 		}
+		switch button {
+		case "Марафон":
+			button = fmt.Sprintf("Марафон: < %s >", marathonName(m.game.marathonGoal))
+		case "Копание":
+			button = fmt.Sprintf("Копание: < %d линий >", m.game.digGoal)
+		case "Невидимка":
+			button = fmt.Sprintf("Невидимка: < %s >", fadeName(m.game.fadeFrames))
+		}
 		if m.game.font != nil {
 			drawText(screen, button, ScreenWidth/2-100, y, clr, m.game.font, i == m.selectedIndex)
 		}
 	}
+
+	// Сид из командной строки действует во всех режимах, о чём стоит напоминать
+	if m.game.isSeedFixed && m.game.font != nil {
+		notice := fmt.Sprintf("Сид зафиксирован для всех режимов: %d", m.game.seed)
+		w, _ := text.Measure(notice, m.game.font, 24)
+		drawText(screen, notice, ScreenWidth/2-int(w/2), 10, color.RGBA{255, 220, 120, 255}, m.game.font, false)
+	}
+	// Сообщение держится до первого выбора в меню
+	if m.game.savedGameNotice != "" && m.game.font != nil {
+		w, _ := text.Measure(m.game.savedGameNotice, m.game.font, 24)
+		drawText(screen, m.game.savedGameNotice, ScreenWidth/2-int(w/2), 40, color.RGBA{255, 220, 120, 255}, m.game.font, false)
+	}
+}
+
+// cycleVariant переключает значение по кругу среди variants стрелками влево и вправо
+func cycleVariant(value *int, variants []int) {
+	i := slices.Index(variants, *value)
+	if inpututil.IsKeyJustPressed(ebiten.KeyArrowLeft) {
+		*value = variants[(i+len(variants)-1)%len(variants)]
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyArrowRight) {
+		*value = variants[(i+1)%len(variants)]
+	}
 }
@@ -0,0 +1,178 @@
+package src
+
+import (
+	"fmt"
+	"github.com/Xu3is/Zetris/src/engine"
+)
+
+// Mode — режим партии
+type Mode int
+
+const (
+	ModeNone      Mode = iota // партия не начата
+	ModeSprint                // 40 линий на время
+	ModeUltra                 // набор очков за ограниченное время
+	ModeMarathon              // скорость растёт с уровнем до цели по линиям
+	ModeDig                   // очистка заданного числа мусорных линий на время
+	ModeSurvival              // мусор поднимается снизу всё чаще
+	ModeMaster                // 20G и звания по правилам TGM
+	ModeInvisible             // зафиксированные клетки исчезают
+	ModeCustom                // параметры из пользовательского режима
+)
+
+// modeKeys содержит названия режимов для файлов сохранений и записей
+var modeKeys = map[Mode]string{
+	ModeNone:      "",
+	ModeSprint:    "sprint",
+	ModeUltra:     "ultra",
+	ModeMarathon:  "marathon",
+	ModeDig:       "dig",
+	ModeSurvival:  "survival",
+	ModeMaster:    "master",
+	ModeInvisible: "invisible",
+	ModeCustom:    "custom",
+}
+
+// MarshalText возвращает название режима
+func (m Mode) MarshalText() ([]byte, error) {
+	key, ok := modeKeys[m]
+	if !ok {
+		return nil, fmt.Errorf("неизвестный режим %d", int(m))
+	}
+	return []byte(key), nil
+}
+
+// UnmarshalText разбирает название режима
+func (m *Mode) UnmarshalText(data []byte) error {
+	for mode, key := range modeKeys {
+		if key == string(data) {
+			*m = mode
+			return nil
+		}
+	}
+	return fmt.Errorf("неизвестный режим %q", data)
+}
+
+// marathonGoals перечисляет варианты марафона в порядке выбора в меню, 0 — бесконечный
+var marathonGoals = []int{150, 200, 0}
+
+// marathonName возвращает название варианта марафона
+func marathonName(goal int) string {
+	if goal == 0 {
+		return "Бесконечный"
+	}
+	return fmt.Sprintf("%d линий", goal)
+}
+
+// digGoals перечисляет варианты копания в порядке выбора в меню
+var digGoals = []int{10, 18, 100}
+
+// digGarbageHeight — сколько мусорных строк поддерживается на поле при копании
+const digGarbageHeight = 10
+
+// survivalRiseInterval — начальный интервал подъёма мусора в режиме выживания
+const survivalRiseInterval = 3 * engine.TPS
+
+// Параметры режима мастера по образцу TGM
+const (
+	masterLineGoal       = 200
+	masterARE            = 30
+	masterLineClearDelay = 41
+	masterLockDelay      = 30
+)
+
+// selectedVariant возвращает вариант режима, выбранный в меню или настройках:
+// минуты Ultra, цель марафона или копания, задержку исчезания невидимки
+func (g *Game) selectedVariant(mode Mode) int {
+	switch mode {
+	case ModeUltra:
+		return g.ultraMinutes
+	case ModeMarathon:
+		return g.marathonGoal
+	case ModeDig:
+		return g.digGoal
+	case ModeInvisible:
+		return g.fadeFrames
+	}
+	return 0
+}
+
+// modeConfig возвращает параметры партии режима mode с вариантом variant
+func (g *Game) modeConfig(mode Mode, variant int) engine.Config {
+	switch mode {
+	case ModeSprint:
+		// Уровень в спринте не растёт, чтобы скорость падения не зависела от темпа игрока
+		return engine.Config{LineGoal: 40, Level: 1}
+	case ModeUltra:
+		return engine.Config{
+			TimeLimit:     variant * 60 * engine.TPS,
+			LinesPerLevel: engine.DefaultLinesPerLevel,
+		}
+	case ModeMarathon:
+		return engine.Config{
+			LineGoal:      variant,
+			LinesPerLevel: engine.DefaultLinesPerLevel,
+		}
+	case ModeDig:
+		return engine.Config{
+			DigGoal:       variant,
+			GarbageHeight: digGarbageHeight,
+			Messiness:     g.messiness,
+		}
+	case ModeSurvival:
+		return engine.Config{
+			RiseInterval: survivalRiseInterval,
+			Messiness:    g.messiness,
+		}
+	case ModeMaster:
+		// Гравитация 20G с первой фигуры, задержки ARE и очистки линий как в TGM
+		return engine.Config{
+			Level:          len(engine.GravityCurve),
+			LinesPerLevel:  engine.DefaultLinesPerLevel,
+			LineGoal:       masterLineGoal,
+			Randomizer:     engine.RandomizerTGM,
+			LockPolicy:     engine.LockStepReset,
+			LockDelay:      masterLockDelay,
+			Ruleset:        engine.RulesetTGM,
+			ARE:            masterARE,
+			LineClearDelay: masterLineClearDelay,
+		}
+	case ModeInvisible:
+		return engine.Config{LinesPerLevel: engine.DefaultLinesPerLevel}
+	}
+	return engine.Config{}
+}
+
+// startMode начинает партию режима mode с вариантом, выбранным в меню.
+// Пользовательский режим запускается из своего экрана через startCustom
+func (g *Game) startMode(mode Mode) {
+	if !g.classicNameEntered {
+		g.state = StateEnterName
+		g.enterName.nextState = StateGame
+		g.enterName.isCustomMode = false
+		g.enterName.start = func() { g.startMode(mode) }
+		return
+	}
+	g.mode = mode
+	g.variant = g.selectedVariant(mode)
+	g.config = g.modeConfig(mode, g.variant)
+	g.restart()
+	g.state = StateGame
+}
+
+// startCustom начинает партию пользовательского режима с параметрами config
+func (g *Game) startCustom(config engine.Config) {
+	g.mode = ModeCustom
+	g.variant = 0
+	g.config = config
+	g.restart()
+	g.state = StateGame
+}
+
+// playerName возвращает имя игрока текущего режима
+func (g *Game) playerName() string {
+	if g.mode == ModeCustom {
+		return g.customPlayerName
+	}
+	return g.classicPlayerName
+}
@@ -5,7 +5,6 @@ import (
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/hajimehoshi/ebiten/v2/text/v2"
 	"image/color"
-	"time"
 )
 
 // PauseMenu представляет меню паузы
@@ -19,7 +18,7 @@ type PauseMenu struct {
 func NewPauseMenu(game *Game) *PauseMenu {
 	return &PauseMenu{
 		game:          game,
-		buttons:       []string{"Продолжить", "Перезапустить", "В меню"},
+		buttons:       []string{"Продолжить", "Перезапустить", "Сохранить и выйти", "В меню"},
 		selectedIndex: 0,
 	}
 }
@@ -45,35 +44,12 @@ func (pm *PauseMenu) Update() error {
 			pm.game.state = StateGame
 			pm.game.isPaused = false
 		case "Перезапустить":
-			pm.game.grid = [gridHeight][gridWidth]string{}
-			pm.game.currentPiece = pm.game.newPiece()
-			pm.game.nextPiece = pm.game.newPiece()
-			pm.game.score = 0
-			if !pm.game.isCustomSpeed {
-				pm.game.fallSpeed = speedLevels[0].fallSpeed
-			}
-			pm.game.isGameOver = false
-			pm.game.isPaused = false
-			pm.game.isPieceGrounded = false
-			pm.game.keyLastAction = make(map[ebiten.Key]time.Time)
-			pm.game.keyPressStart = make(map[ebiten.Key]time.Time)
-			pm.game.clearedLines = 0
+			pm.game.restart()
 			pm.game.state = StateGame
+		case "Сохранить и выйти":
+			pm.game.saveAndQuit()
 		case "В меню":
-			pm.game.state = StateMenu
-			pm.game.grid = [gridHeight][gridWidth]string{}
-			pm.game.currentPiece = pm.game.newPiece()
-			pm.game.nextPiece = pm.game.newPiece()
-			pm.game.score = 0
-			pm.game.fallSpeed = speedLevels[0].fallSpeed
-			pm.game.isGameOver = false
-			pm.game.isPaused = false
-			pm.game.isPieceGrounded = false
-			pm.game.keyLastAction = make(map[ebiten.Key]time.Time)
-			pm.game.keyPressStart = make(map[ebiten.Key]time.Time)
-			pm.game.clearedLines = 0
-			pm.game.isCustomSpeed = false
-			pm.game.isLimitedTo40Lines = false
+			pm.game.quitToMenu()
 		}
 	}
 
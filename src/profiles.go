@@ -0,0 +1,100 @@
+package src
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Xu3is/Zetris/src/engine"
+	"github.com/hajimehoshi/ebiten/v2"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// profilesVersion — версия формата файла профилей
+const profilesVersion = 1
+
+// profilesFile — имя файла профилей в каталоге настроек игры
+const profilesFile = "profiles.json"
+
+// Profile — настройки игрока, которые хранятся под его именем
+type Profile struct {
+	Handling engine.Handling `json:"handling"`
+	HoldKeys []ebiten.Key    `json:"holdKeys,omitempty"` // клавиши запаса, пусто — раскладка по умолчанию
+}
+
+// savedProfiles — содержимое файла профилей. Профиль с пустым именем
+// действует, пока игрок не ввёл имя
+type savedProfiles struct {
+	Version  int                `json:"version"`
+	Profiles map[string]Profile `json:"profiles"`
+}
+
+// loadProfiles загружает профили с диска. Повреждённый файл сохраняется
+// под отдельным именем, а игра начинает с настройками по умолчанию
+func (g *Game) loadProfiles() {
+	dir, err := configDir()
+	if err != nil {
+		log.Printf("Не удалось определить каталог настроек: %v", err)
+		return
+	}
+	path := filepath.Join(dir, profilesFile)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return
+	}
+	if err != nil {
+		log.Printf("Не удалось прочитать профили: %v", err)
+		return
+	}
+	var saved savedProfiles
+	err = json.Unmarshal(data, &saved)
+	if err == nil && saved.Version != profilesVersion {
+		err = fmt.Errorf("неизвестная версия %d", saved.Version)
+	}
+	if err != nil {
+		log.Printf("Файл профилей повреждён: %v", err)
+		keepCorrupt(path)
+		return
+	}
+	for name, p := range saved.Profiles {
+		g.profiles[name] = p
+	}
+}
+
+// saveProfiles сохраняет профили на диск
+func (g *Game) saveProfiles() {
+	data, err := json.MarshalIndent(savedProfiles{Version: profilesVersion, Profiles: g.profiles}, "", "  ")
+	if err != nil {
+		log.Printf("Не удалось подготовить профили к записи: %v", err)
+		return
+	}
+	dir, err := configDir()
+	if err != nil {
+		log.Printf("Не удалось определить каталог настроек: %v", err)
+		return
+	}
+	if err := writeFileAtomic(filepath.Join(dir, profilesFile), data); err != nil {
+		log.Printf("Не удалось сохранить профили: %v", err)
+	}
+}
+
+// selectProfile делает профиль name текущим и применяет его настройки.
+// У нового игрока остаются текущие настройки, пока он их не изменит
+func (g *Game) selectProfile(name string) {
+	g.profile = name
+	if p, ok := g.profiles[name]; ok {
+		g.handling = p.Handling
+		g.bindings = defaultBindings()
+		if len(p.HoldKeys) > 0 {
+			g.bindings.restore(engine.InputHold, p.HoldKeys)
+		}
+	}
+}
+
+// storeProfile записывает текущие настройки в профиль игрока и сохраняет профили
+func (g *Game) storeProfile() {
+	g.profiles[g.profile] = Profile{Handling: g.handling, HoldKeys: g.bindings.keys(engine.InputHold)}
+	g.saveProfiles()
+}
@@ -0,0 +1,323 @@
+package src
+
+import (
+	"fmt"
+	"github.com/Xu3is/Zetris/src/engine"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"image/color"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	replaysDir       = "replays"
+	replayExt        = ".zr"
+	replayListRows   = 10             // сколько записей видно в списке одновременно
+	replaySeekFrames = 5 * engine.TPS // шаг перемотки стрелками
+)
+
+// replaySpeeds перечисляет скорости воспроизведения записей
+var replaySpeeds = []float64{0.25, 0.5, 1, 2, 4}
+
+// replayPath возвращает путь к файлу записи в каталоге настроек
+func replayPath(name string) (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, replaysDir, name), nil
+}
+
+// saveReplay сохраняет запись закончившейся партии и возвращает имя файла или "" при ошибке
+func (g *Game) saveReplay() string {
+	replay := g.engine.Replay()
+	replay.Player = g.playerName()
+	mode, _ := g.mode.MarshalText()
+	replay.Mode = string(mode)
+	replay.Variant = g.variant
+	replay.Date = time.Now()
+
+	data, err := replay.MarshalBinary()
+	if err != nil {
+		log.Printf("Не удалось подготовить запись партии: %v", err)
+		return ""
+	}
+	// Время с наносекундами и сид не дают двум партиям получить одно имя
+	name := fmt.Sprintf("%s-%016x%s", replay.Date.Format("20060102-150405.000000000"), replay.Config.Seed, replayExt)
+	path, err := replayPath(name)
+	if err != nil {
+		log.Printf("Не удалось определить каталог записей: %v", err)
+		return ""
+	}
+	if err := writeFileAtomic(path, data); err != nil {
+		log.Printf("Не удалось сохранить запись партии: %v", err)
+		return ""
+	}
+	return name
+}
+
+// loadReplay читает запись партии из каталога записей
+func loadReplay(name string) (*engine.Replay, error) {
+	path, err := replayPath(name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	replay := &engine.Replay{}
+	if err := replay.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	return replay, nil
+}
+
+// replayMode возвращает режим и вариант, в котором сыграна запись.
+// Записи без известного режима воспроизводятся как обычная партия
+func replayMode(replay *engine.Replay) (Mode, int) {
+	var mode Mode
+	if err := mode.UnmarshalText([]byte(replay.Mode)); err != nil {
+		return ModeNone, 0
+	}
+	return mode, replay.Variant
+}
+
+// replayTitle возвращает название режима записи для списка и проигрывателя
+func replayTitle(replay *engine.Replay) string {
+	mode, variant := replayMode(replay)
+	if info, ok := modeBoard(mode, variant, replay.Config); ok {
+		return info.title
+	}
+	return replay.Mode
+}
+
+// replayItem — строка списка записей
+type replayItem struct {
+	name   string
+	replay *engine.Replay
+}
+
+// ReplayBrowser представляет список сохранённых записей партий
+type ReplayBrowser struct {
+	game          *Game
+	items         []replayItem
+	selectedIndex int
+}
+
+// NewReplayBrowser создает новый список записей
+func NewReplayBrowser(game *Game) *ReplayBrowser {
+	return &ReplayBrowser{game: game}
+}
+
+// open перечитывает каталог записей, новые сверху, и показывает список
+func (rb *ReplayBrowser) open() {
+	rb.items = nil
+	rb.selectedIndex = 0
+	rb.game.state = StateReplays
+
+	dir, err := replayPath("")
+	if err != nil {
+		log.Printf("Не удалось определить каталог записей: %v", err)
+		return
+	}
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("Не удалось прочитать каталог записей: %v", err)
+		}
+		return
+	}
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), replayExt) {
+			continue
+		}
+		replay, err := loadReplay(file.Name())
+		if err != nil {
+			log.Printf("Пропущена запись %s: %v", file.Name(), err)
+			continue
+		}
+		rb.items = append(rb.items, replayItem{name: file.Name(), replay: replay})
+	}
+	slices.Reverse(rb.items)
+}
+
+// Update обновляет список записей
+func (rb *ReplayBrowser) Update() error {
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		rb.game.state = StateMenu
+		return nil
+	}
+	if len(rb.items) == 0 {
+		return nil
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyArrowUp) {
+		rb.selectedIndex--
+		if rb.selectedIndex < 0 {
+			rb.selectedIndex = len(rb.items) - 1
+		}
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyArrowDown) {
+		rb.selectedIndex++
+		if rb.selectedIndex >= len(rb.items) {
+			rb.selectedIndex = 0
+		}
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
+		rb.game.replayViewer.start(rb.items[rb.selectedIndex].replay)
+	}
+	return nil
+}
+
+// Draw отрисовывает список записей
+func (rb *ReplayBrowser) Draw(screen *ebiten.Image) {
+	overlay := ebiten.NewImage(ScreenWidth, ScreenHeight)
+	overlay.Fill(color.RGBA{20, 30, 50, 192})
+	screen.DrawImage(overlay, &ebiten.DrawImageOptions{})
+
+	if rb.game.font == nil {
+		return
+	}
+
+	headerText := "Записи"
+	w, _ := text.Measure(headerText, rb.game.font, 32)
+	drawText(screen, headerText, ScreenWidth/2-int(w/2), 40, color.RGBA{180, 220, 255, 255}, rb.game.font, false)
+
+	if len(rb.items) == 0 {
+		emptyText := "Записей пока нет"
+		w, _ = text.Measure(emptyText, rb.game.font, 24)
+		drawText(screen, emptyText, ScreenWidth/2-int(w/2), ScreenHeight/2-20, color.RGBA{180, 220, 255, 255}, rb.game.font, false)
+		return
+	}
+
+	// Список прокручивается так, чтобы выбранная запись оставалась видимой
+	first := max(0, min(rb.selectedIndex-replayListRows/2, len(rb.items)-replayListRows))
+	for i := first; i < min(first+replayListRows, len(rb.items)); i++ {
+		r := rb.items[i].replay
+		line := fmt.Sprintf("%s — %s, %s, %s", replayTitle(r), recordName(HighScore{Name: r.Player}), formatFrames(r.Frames), r.Date.Format("02.01 15:04"))
+		var clr color.Color = color.RGBA{180, 220, 255, 255}
+		if i == rb.selectedIndex {
+			clr = color.RGBA{100, 200, 255, 255}
+		}
+		drawText(screen, line, 30, 100+(i-first)*40, clr, rb.game.font, i == rb.selectedIndex)
+	}
+	hint := "Enter: Смотреть, Esc: В меню"
+	w, _ = text.Measure(hint, rb.game.font, 24)
+	drawText(screen, hint, ScreenWidth/2-int(w/2), ScreenHeight-40, color.RGBA{180, 220, 255, 255}, rb.game.font, false)
+}
+
+// ReplayViewer воспроизводит запись партии на поле игры
+type ReplayViewer struct {
+	game     *Game
+	player   *engine.Player
+	isPaused bool
+	speed    int     // индекс в replaySpeeds
+	progress float64 // накопленная доля кадра при скорости не кратной единице
+}
+
+// NewReplayViewer создает новый проигрыватель записей
+func NewReplayViewer(game *Game) *ReplayViewer {
+	return &ReplayViewer{
+		game:  game,
+		speed: slices.Index(replaySpeeds, 1),
+	}
+}
+
+// start начинает воспроизведение записи с первого кадра
+func (rv *ReplayViewer) start(replay *engine.Replay) {
+	rv.game.quitToMenu()
+	// Режим восстанавливается, чтобы поле, часы и звание выглядели как в самой партии
+	rv.game.mode, rv.game.variant = replayMode(replay)
+	rv.player = engine.NewPlayer(replay)
+	rv.player.Subscribe(rv.game.onLock)
+	rv.isPaused = false
+	rv.progress = 0
+	rv.game.config = replay.Config
+	rv.game.engine = rv.player.Engine()
+	rv.game.state = StateReplay
+}
+
+// step продвигает запись на кадр вместе с таймерами подписей
+func (rv *ReplayViewer) step() {
+	if rv.player.Step() {
+		if rv.game.lockLabelTimer > 0 {
+			rv.game.lockLabelTimer--
+		}
+		if rv.game.flashTimer > 0 {
+			rv.game.flashTimer--
+		}
+	}
+}
+
+// seek перематывает запись на frames кадров вперёд или назад
+func (rv *ReplayViewer) seek(frames int) {
+	rv.player.Seek(max(rv.player.Engine().Frames()+frames, 0))
+	rv.game.engine = rv.player.Engine()
+	rv.game.lockLabelTimer = 0
+}
+
+// Update обрабатывает управление воспроизведением: пауза, покадровый шаг, скорость и перемотка
+func (rv *ReplayViewer) Update() error {
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		rv.game.quitToMenu()
+		rv.game.replayBrowser.open()
+		return nil
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeySpace) {
+		rv.isPaused = !rv.isPaused
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyArrowUp) && rv.speed < len(replaySpeeds)-1 {
+		rv.speed++
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyArrowDown) && rv.speed > 0 {
+		rv.speed--
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyArrowLeft) {
+		rv.seek(-replaySeekFrames)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyArrowRight) {
+		rv.seek(replaySeekFrames)
+	}
+
+	if rv.isPaused {
+		if inpututil.IsKeyJustPressed(ebiten.KeyPeriod) {
+			rv.step()
+		}
+		return nil
+	}
+	rv.progress += replaySpeeds[rv.speed]
+	for rv.progress >= 1 {
+		rv.progress--
+		rv.step()
+	}
+	return nil
+}
+
+// Draw отрисовывает состояние воспроизведения поверх поля
+func (rv *ReplayViewer) Draw(screen *ebiten.Image) {
+	if rv.game.font == nil {
+		return
+	}
+	replay := rv.player.Replay()
+	status := "Просмотр"
+	if rv.isPaused {
+		status = "Пауза"
+	}
+	if rv.player.Done() {
+		status = "Конец"
+	}
+	header := fmt.Sprintf("%s, %s: %s x%g  %s / %s", replayTitle(replay), recordName(HighScore{Name: replay.Player}), status,
+		replaySpeeds[rv.speed], formatFrames(rv.player.Engine().Frames()), formatFrames(replay.Frames))
+	w, _ := text.Measure(header, rv.game.font, 24)
+	drawText(screen, header, ScreenWidth/2-int(w/2), 10, color.RGBA{180, 220, 255, 255}, rv.game.font, false)
+
+	hint := "Пробел: пауза, .: кадр, ↑↓: скорость, ←→: 5 с, Esc"
+	w, _ = text.Measure(hint, rv.game.font, 24)
+	drawText(screen, hint, ScreenWidth/2-int(w/2), ScreenHeight-30, color.RGBA{180, 220, 255, 255}, rv.game.font, false)
+}
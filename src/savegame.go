@@ -0,0 +1,192 @@
+package src
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Xu3is/Zetris/src/engine"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// savedGameVersion — версия формата сохранённой партии
+const savedGameVersion = 1
+
+// savedGameFile — имя файла сохранённой партии в каталоге настроек игры
+const savedGameFile = "savegame.json"
+
+// savedGame — прерванная партия. Движок детерминирован, поэтому вместо поля,
+// фигур и состояния генератора хранится запись партии: при продолжении она
+// пересчитывается до сохранённого кадра и сверяется с итогом из записи
+type savedGame struct {
+	Version        int    `json:"version"`
+	Replay         []byte `json:"replay"` // engine.Replay в формате MarshalBinary
+	PlayerName     string `json:"playerName"`
+	Mode           Mode   `json:"mode"`
+	Variant        int    `json:"variant"`
+	LockLabel      string `json:"lockLabel,omitempty"`
+	LockLabelTimer int    `json:"lockLabelTimer,omitempty"`
+	FlashTimer     int    `json:"flashTimer,omitempty"`
+}
+
+// savedGamePath возвращает путь к файлу сохранённой партии
+func savedGamePath() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, savedGameFile), nil
+}
+
+// checkSavedGame сообщает, есть ли на диске партия, которую можно продолжить.
+// Партия, которую продолжить нельзя, убирается с дороги
+func (g *Game) checkSavedGame() bool {
+	_, _, err := readSavedGame()
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		g.discardSavedGame(err)
+	}
+	return err == nil
+}
+
+// discardSavedGame переименовывает сохранённую партию, которую нельзя продолжить,
+// чтобы «Продолжить» не исчезало молча, и сообщает игроку причину в главном меню
+func (g *Game) discardSavedGame(err error) {
+	log.Printf("Сохранённую партию нельзя продолжить: %v", err)
+	if path, err := savedGamePath(); err == nil {
+		keepCorrupt(path)
+	}
+	g.hasSavedGame = false
+	g.savedGameNotice = "Сохранённую партию не удалось продолжить"
+	if errors.Is(err, engine.ErrRulesVersion) {
+		g.savedGameNotice = "Сохранённая партия сделана в другой версии игры"
+	}
+}
+
+// saveGame сохраняет текущую партию на диск. Возвращает false при ошибке
+func (g *Game) saveGame() bool {
+	data, err := g.engine.Replay().MarshalBinary()
+	if err != nil {
+		log.Printf("Не удалось подготовить партию к сохранению: %v", err)
+		return false
+	}
+	saved := savedGame{
+		Version:        savedGameVersion,
+		Replay:         data,
+		PlayerName:     g.playerName(),
+		Mode:           g.mode,
+		Variant:        g.variant,
+		LockLabel:      g.lockLabel,
+		LockLabelTimer: g.lockLabelTimer,
+		FlashTimer:     g.flashTimer,
+	}
+	if data, err = json.MarshalIndent(saved, "", "  "); err != nil {
+		log.Printf("Не удалось подготовить партию к сохранению: %v", err)
+		return false
+	}
+	path, err := savedGamePath()
+	if err != nil {
+		log.Printf("Не удалось определить каталог настроек: %v", err)
+		return false
+	}
+	if err := writeFileAtomic(path, data); err != nil {
+		log.Printf("Не удалось сохранить партию: %v", err)
+		return false
+	}
+	return true
+}
+
+// readSavedGame читает сохранённую партию и проверяет, что её формат и
+// версия правил движка совпадают с текущими
+func readSavedGame() (*savedGame, *engine.Replay, error) {
+	path, err := savedGamePath()
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	var saved savedGame
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, nil, err
+	}
+	if saved.Version != savedGameVersion {
+		return nil, nil, fmt.Errorf("неизвестная версия %d", saved.Version)
+	}
+	replay := &engine.Replay{}
+	if err := replay.UnmarshalBinary(saved.Replay); err != nil {
+		return nil, nil, err
+	}
+	if err := replay.CheckRules(); err != nil {
+		return nil, nil, err
+	}
+	return &saved, replay, nil
+}
+
+// loadSavedGame читает сохранённую партию и пересчитывает её движок до
+// сохранённого кадра. Файл удаляется только после успешного восстановления,
+// чтобы партию нельзя было продолжить дважды, но и нельзя было потерять из-за ошибки
+func loadSavedGame() (*savedGame, *engine.Engine, error) {
+	saved, replay, err := readSavedGame()
+	if err != nil {
+		return nil, nil, err
+	}
+	e := engine.NewPlayer(replay).Run()
+	if got := e.Result(); got != replay.Result {
+		return nil, nil, fmt.Errorf("партия восстановлена не в том состоянии: счёт %d/%d, линии %d/%d, кадры %d/%d",
+			got.Score, replay.Result.Score, got.Lines, replay.Result.Lines, got.Frames, replay.Result.Frames)
+	}
+	path, err := savedGamePath()
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		log.Printf("Не удалось удалить сохранённую партию: %v", err)
+	}
+	return saved, e, nil
+}
+
+// saveAndQuit сохраняет партию из меню паузы и возвращает в главное меню
+func (g *Game) saveAndQuit() {
+	if g.saveGame() {
+		g.hasSavedGame = true
+	}
+	g.quitToMenu()
+}
+
+// continueGame продолжает сохранённую партию с того же кадра
+func (g *Game) continueGame() {
+	saved, e, err := loadSavedGame()
+	g.hasSavedGame = false
+	if errors.Is(err, fs.ErrNotExist) {
+		log.Printf("Сохранённая партия пропала с диска: %v", err)
+		return
+	}
+	if err != nil {
+		g.discardSavedGame(err)
+		return
+	}
+	g.mode = saved.Mode
+	g.variant = saved.Variant
+	if g.mode == ModeCustom {
+		g.customPlayerName = saved.PlayerName
+		g.customNameEntered = true
+	} else {
+		g.classicPlayerName = saved.PlayerName
+		g.classicNameEntered = true
+	}
+	g.selectProfile(saved.PlayerName)
+
+	g.config = e.Replay().Config
+	g.engine = e
+	g.engine.Subscribe(g.onLock)
+	g.lockLabel = saved.LockLabel
+	g.lockLabelTimer = saved.LockLabelTimer
+	g.flashTimer = saved.FlashTimer
+	g.isRecorded = false
+	g.newRecordPlace = -1
+	g.isPaused = false
+	g.state = StateGame
+}
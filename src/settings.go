@@ -2,11 +2,16 @@ package src
 
 import (
 	"fmt"
+	"github.com/Xu3is/Zetris/src/engine"
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/audio"
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
 	"image/color"
 	"log"
+	"maps"
+	"slices"
+	"strings"
 )
 
 // SettingsMenu представляет экран настроек
@@ -17,8 +22,24 @@ type SettingsMenu struct {
 	resolutions   [][2]int
 	resIndex      int
 	elements      []string
+	bindings      keyBindings // раскладка, которая вступит в силу после «Применить»
+	isBinding     bool        // ожидается нажатие новой клавиши запаса
+	busyKey       ebiten.Key  // последняя нажатая клавиша, занятая другим действием
+	isKeyBusy     bool
+	previews      int
+	showGhost     bool
+	ghostOpacity  float32
+	handling      engine.Handling
+	ultraMinutes  int
+	messiness     float64
 }
 
+// softDropFactors перечисляет множители мягкого сброса, доступные в настройках
+var softDropFactors = []int{1, 2, 5, 6, 10, 20, 40, engine.SoftDropInfinite}
+
+// maxUltraMinutes — наибольшая длительность Ultra, доступная в настройках
+const maxUltraMinutes = 10
+
 // NewSettingsMenu создает новое меню настроек
 func NewSettingsMenu(game *Game) *SettingsMenu {
 	return &SettingsMenu{
@@ -33,13 +54,52 @@ func NewSettingsMenu(game *Game) *SettingsMenu {
 			{1280, 1024},
 			{1600, 900},
 		},
-		resIndex: 0,
-		elements: []string{"Громкость", "Разрешение", "Применить"},
+		resIndex:     0,
+		previews:     game.previews,
+		showGhost:    game.showGhost,
+		ghostOpacity: game.ghostOpacity,
+		handling:     game.handling,
+		bindings:     maps.Clone(game.bindings),
+		ultraMinutes: game.ultraMinutes,
+		messiness:    game.messiness,
+		elements: []string{
+			"Громкость", "Разрешение", "Очередь", "Тень", "Прозрачность тени",
+			"DAS", "ARR", "Мягкий сброс", "DCD", "Сохранять DAS",
+			"Время Ultra", "Хаос мусора", "Клавиша запаса", "Применить",
+		},
 	}
 }
 
+// open показывает меню настроек с текущими значениями игры и профиля
+func (sm *SettingsMenu) open() {
+	sm.previews = sm.game.previews
+	sm.showGhost = sm.game.showGhost
+	sm.ghostOpacity = sm.game.ghostOpacity
+	sm.handling = sm.game.handling
+	sm.bindings = maps.Clone(sm.game.bindings)
+	sm.ultraMinutes = sm.game.ultraMinutes
+	sm.messiness = sm.game.messiness
+	sm.isBinding = false
+	sm.game.state = StateSettings
+}
+
 // Update обновляет меню настроек
 func (sm *SettingsMenu) Update() error {
+	if sm.isBinding {
+		for _, key := range inpututil.AppendJustPressedKeys(nil) {
+			// Занятую клавишу не отбираем у другого действия, а ждём другую
+			if key != ebiten.KeyEscape && !sm.bindings.bind(key, engine.InputHold) {
+				sm.busyKey = key
+				sm.isKeyBusy = true
+				break
+			}
+			sm.isBinding = false
+			sm.isKeyBusy = false
+			break
+		}
+		return nil
+	}
+
 	if inpututil.IsKeyJustPressed(ebiten.KeyArrowUp) {
 		sm.selectedIndex--
 		if sm.selectedIndex < 0 {
@@ -53,7 +113,9 @@ func (sm *SettingsMenu) Update() error {
 		}
 	}
 
-	if sm.selectedIndex == 0 { // Громкость
+	element := sm.elements[sm.selectedIndex]
+
+	if element == "Громкость" {
 		if inpututil.IsKeyJustPressed(ebiten.KeyArrowLeft) {
 			sm.volume -= 0.05
 			if sm.volume < 0 {
@@ -68,7 +130,7 @@ func (sm *SettingsMenu) Update() error {
 		}
 	}
 
-	if sm.selectedIndex == 1 { // Разрешение
+	if element == "Разрешение" {
 		if inpututil.IsKeyJustPressed(ebiten.KeyArrowLeft) {
 			sm.resIndex--
 			if sm.resIndex < 0 {
@@ -83,8 +145,89 @@ func (sm *SettingsMenu) Update() error {
 		}
 	}
 
+	if element == "Очередь" {
+		if inpututil.IsKeyJustPressed(ebiten.KeyArrowLeft) && sm.previews > 1 {
+			sm.previews--
+		}
+		if inpututil.IsKeyJustPressed(ebiten.KeyArrowRight) && sm.previews < engine.MaxPreviews {
+			sm.previews++
+		}
+	}
+
+	if element == "Тень" {
+		if inpututil.IsKeyJustPressed(ebiten.KeyArrowLeft) || inpututil.IsKeyJustPressed(ebiten.KeyArrowRight) {
+			sm.showGhost = !sm.showGhost
+		}
+	}
+
+	if element == "Прозрачность тени" {
+		if inpututil.IsKeyJustPressed(ebiten.KeyArrowLeft) {
+			sm.ghostOpacity -= 0.1
+			if sm.ghostOpacity < 0.1 {
+				sm.ghostOpacity = 0.1
+			}
+		}
+		if inpututil.IsKeyJustPressed(ebiten.KeyArrowRight) {
+			sm.ghostOpacity += 0.1
+			if sm.ghostOpacity > 1 {
+				sm.ghostOpacity = 1
+			}
+		}
+	}
+
+	if element == "DAS" {
+		adjustFrames(&sm.handling.DAS, 30)
+	}
+
+	if element == "ARR" {
+		adjustFrames(&sm.handling.ARR, 10)
+	}
+
+	if element == "Мягкий сброс" {
+		i := slices.Index(softDropFactors, sm.handling.SDF)
+		if inpututil.IsKeyJustPressed(ebiten.KeyArrowLeft) && i > 0 {
+			sm.handling.SDF = softDropFactors[i-1]
+		}
+		if inpututil.IsKeyJustPressed(ebiten.KeyArrowRight) && i < len(softDropFactors)-1 {
+			sm.handling.SDF = softDropFactors[i+1]
+		}
+	}
+
+	if element == "DCD" {
+		adjustFrames(&sm.handling.DCD, 20)
+	}
+
+	if element == "Сохранять DAS" {
+		if inpututil.IsKeyJustPressed(ebiten.KeyArrowLeft) || inpututil.IsKeyJustPressed(ebiten.KeyArrowRight) {
+			sm.handling.PreserveDAS = !sm.handling.PreserveDAS
+		}
+	}
+
+	if element == "Время Ultra" {
+		if inpututil.IsKeyJustPressed(ebiten.KeyArrowLeft) && sm.ultraMinutes > 1 {
+			sm.ultraMinutes--
+		}
+		if inpututil.IsKeyJustPressed(ebiten.KeyArrowRight) && sm.ultraMinutes < maxUltraMinutes {
+			sm.ultraMinutes++
+		}
+	}
+
+	if element == "Хаос мусора" {
+		if inpututil.IsKeyJustPressed(ebiten.KeyArrowLeft) {
+			sm.messiness = max(sm.messiness-0.1, 0)
+		}
+		if inpututil.IsKeyJustPressed(ebiten.KeyArrowRight) {
+			sm.messiness = min(sm.messiness+0.1, 1)
+		}
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) && element == "Клавиша запаса" {
+		sm.isBinding = true
+		return nil
+	}
+
 	// Применение настроек
-	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) && sm.selectedIndex == 2 {
+	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) && element == "Применить" {
 		sm.applySettings()
 		sm.game.state = StateMenu
 	}
@@ -104,11 +247,16 @@ func (sm *SettingsMenu) Draw(screen *ebiten.Image) {
 	screen.DrawImage(overlay, &ebiten.DrawImageOptions{})
 
 	if sm.game.font != nil {
-		drawText(screen, "Настройки", ScreenWidth/2-70, ScreenHeight/2-150, color.RGBA{180, 220, 255, 255}, sm.game.font, false)
+		header := "Настройки"
+		if sm.game.profile != "" {
+			header = fmt.Sprintf("Настройки игрока %s", sm.game.profile)
+		}
+		w, _ := text.Measure(header, sm.game.font, 24)
+		drawText(screen, header, ScreenWidth/2-int(w/2), ScreenHeight/2-260, color.RGBA{180, 220, 255, 255}, sm.game.font, false)
 	}
 
 	for i, element := range sm.elements {
-		y := ScreenHeight/2 - 50 + i*40
+		y := ScreenHeight/2 - 200 + i*32
 		var clr color.Color = color.RGBA{180, 220, 255, 255}
 		if i == sm.selectedIndex {
 			clr = color.RGBA{100, 200, 255, 255}
@@ -119,6 +267,54 @@ func (sm *SettingsMenu) Draw(screen *ebiten.Image) {
 			text = fmt.Sprintf("Громкость: %.0f%%", sm.volume*100)
 		case "Разрешение":
 			text = fmt.Sprintf("Разрешение: %dx%d", sm.resolutions[sm.resIndex][0], sm.resolutions[sm.resIndex][1])
+		case "Очередь":
+			text = fmt.Sprintf("Очередь: %d", sm.previews)
+		case "Тень":
+			if sm.showGhost {
+				text = "Тень: Вкл"
+			} else {
+				text = "Тень: Выкл"
+			}
+		case "Прозрачность тени":
+			text = fmt.Sprintf("Прозрачность тени: %.0f%%", sm.ghostOpacity*100)
+		case "DAS":
+			text = fmt.Sprintf("DAS: %d мс", engine.FramesToDuration(sm.handling.DAS).Milliseconds())
+		case "ARR":
+			if sm.handling.ARR == 0 {
+				text = "ARR: Мгновенно"
+			} else {
+				text = fmt.Sprintf("ARR: %d мс", engine.FramesToDuration(sm.handling.ARR).Milliseconds())
+			}
+		case "Мягкий сброс":
+			if sm.handling.SDF >= engine.SoftDropInfinite {
+				text = "Мягкий сброс: ∞"
+			} else {
+				text = fmt.Sprintf("Мягкий сброс: x%d", sm.handling.SDF)
+			}
+		case "DCD":
+			text = fmt.Sprintf("DCD: %d мс", engine.FramesToDuration(sm.handling.DCD).Milliseconds())
+		case "Сохранять DAS":
+			if sm.handling.PreserveDAS {
+				text = "Сохранять DAS: Да"
+			} else {
+				text = "Сохранять DAS: Нет"
+			}
+		case "Время Ultra":
+			text = fmt.Sprintf("Время Ultra: %d мин", sm.ultraMinutes)
+		case "Хаос мусора":
+			text = fmt.Sprintf("Хаос мусора: %.0f%%", sm.messiness*100)
+		case "Клавиша запаса":
+			if sm.isBinding && sm.isKeyBusy {
+				text = fmt.Sprintf("Клавиша запаса: %s занята, нажмите другую", sm.busyKey)
+			} else if sm.isBinding {
+				text = "Клавиша запаса: нажмите клавишу"
+			} else {
+				var names []string
+				for _, key := range sm.bindings.keys(engine.InputHold) {
+					names = append(names, key.String())
+				}
+				text = fmt.Sprintf("Клавиша запаса: %s", strings.Join(names, ", "))
+			}
 		case "Применить":
 			text = "Применить"
 		}
@@ -136,6 +332,24 @@ func (sm *SettingsMenu) applySettings() {
 			log.Printf("Установлена громкость %.2f для плеера", sm.volume)
 		}
 	}
+	sm.game.previews = sm.previews
+	sm.game.showGhost = sm.showGhost
+	sm.game.ghostOpacity = sm.ghostOpacity
+	sm.game.handling = sm.handling
+	sm.game.bindings = maps.Clone(sm.bindings)
+	sm.game.storeProfile()
+	sm.game.ultraMinutes = sm.ultraMinutes
+	sm.game.messiness = sm.messiness
 	width, height := sm.resolutions[sm.resIndex][0], sm.resolutions[sm.resIndex][1]
 	ebiten.SetWindowSize(width, height)
 }
+
+// adjustFrames изменяет задержку в кадрах стрелками влево и вправо в пределах от 0 до limit
+func adjustFrames(value *int, limit int) {
+	if inpututil.IsKeyJustPressed(ebiten.KeyArrowLeft) && *value > 0 {
+		*value--
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyArrowRight) && *value < limit {
+		*value++
+	}
+}
@@ -0,0 +1,158 @@
+package src
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// highScoresVersion — версия формата файла рекордов. Её нужно повышать при
+// несовместимых изменениях и дописывать перенос старых файлов в loadHighScores
+const highScoresVersion = 1
+
+// highScoresFile — имя файла рекордов в каталоге настроек игры
+const highScoresFile = "highscores.json"
+
+// savedHighScores — содержимое файла рекордов
+type savedHighScores struct {
+	Version      int                     `json:"version"`
+	Leaderboards map[string]*Leaderboard `json:"leaderboards"`
+}
+
+// configDir возвращает каталог настроек игры, например ~/.config/zetris в Linux
+func configDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "zetris"), nil
+}
+
+// highScoresBackupExt — расширение последней исправной копии файла рекордов
+const highScoresBackupExt = ".bak"
+
+// decodeHighScores разбирает файл рекордов
+func decodeHighScores(data []byte) (map[string]*Leaderboard, error) {
+	var saved savedHighScores
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, err
+	}
+	if saved.Version != highScoresVersion {
+		return nil, fmt.Errorf("неизвестная версия %d", saved.Version)
+	}
+	return saved.Leaderboards, nil
+}
+
+// loadHighScores загружает рекорды с диска. Повреждённый файл сохраняется под
+// отдельным именем с временем обнаружения, а рекорды восстанавливаются из
+// последней исправной копии, которую оставляет saveHighScores
+func (g *Game) loadHighScores() {
+	dir, err := configDir()
+	if err != nil {
+		log.Printf("Не удалось определить каталог настроек: %v", err)
+		return
+	}
+	path := filepath.Join(dir, highScoresFile)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return
+	}
+	if err != nil {
+		log.Printf("Не удалось прочитать рекорды: %v", err)
+		return
+	}
+
+	boards, err := decodeHighScores(data)
+	if err != nil {
+		log.Printf("Файл рекордов повреждён: %v", err)
+		keepCorrupt(path)
+		backup, err := os.ReadFile(path + highScoresBackupExt)
+		if err == nil {
+			boards, err = decodeHighScores(backup)
+		}
+		if err != nil {
+			log.Printf("Не удалось восстановить рекорды из копии: %v", err)
+			return
+		}
+		log.Printf("Рекорды восстановлены из последней исправной копии")
+	}
+
+	for key, lb := range boards {
+		if lb != nil {
+			g.leaderboards[key] = lb
+		}
+	}
+}
+
+// saveHighScores сохраняет рекорды на диск. Файл записывается во временный
+// и переименовывается, чтобы сбой посреди записи не испортил прежние рекорды.
+// Прежний файл, если он исправен, остаётся рядом как последняя исправная копия
+func (g *Game) saveHighScores() {
+	saved := savedHighScores{
+		Version:      highScoresVersion,
+		Leaderboards: g.leaderboards,
+	}
+	data, err := json.MarshalIndent(saved, "", "  ")
+	if err != nil {
+		log.Printf("Не удалось подготовить рекорды к записи: %v", err)
+		return
+	}
+	dir, err := configDir()
+	if err != nil {
+		log.Printf("Не удалось определить каталог настроек: %v", err)
+		return
+	}
+	path := filepath.Join(dir, highScoresFile)
+	if old, err := os.ReadFile(path); err == nil {
+		if _, err := decodeHighScores(old); err == nil {
+			if err := writeFileAtomic(path+highScoresBackupExt, old); err != nil {
+				log.Printf("Не удалось сохранить копию рекордов: %v", err)
+			}
+		}
+	}
+	if err := writeFileAtomic(path, data); err != nil {
+		log.Printf("Не удалось сохранить рекорды: %v", err)
+	}
+}
+
+// keepCorrupt убирает повреждённый файл с дороги, сохраняя его под отдельным
+// именем с временем обнаружения, чтобы следующая порча его не затёрла
+func keepCorrupt(path string) {
+	corrupt := path + ".corrupt-" + time.Now().Format("20060102-150405.000")
+	if err := os.Rename(path, corrupt); err != nil {
+		log.Printf("Не удалось сохранить копию повреждённого файла: %v", err)
+		return
+	}
+	log.Printf("Повреждённый файл сохранён как %s", filepath.Base(corrupt))
+}
+
+// writeFileAtomic записывает data во временный файл в том же каталоге и
+// заменяет им path, так что на диске всегда лежит либо старый, либо новый файл целиком
+func writeFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
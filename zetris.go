@@ -1,19 +1,27 @@
 package main
 
 import (
+	"flag"
 	"github.com/Xu3is/Zetris/src"
+	"github.com/Xu3is/Zetris/src/engine"
 	"github.com/hajimehoshi/ebiten/v2"
 	"log"
-	"math/rand"
-	"time"
 )
 
 func main() {
-	rand.Seed(time.Now().UnixNano())
+	seed := flag.Uint64("seed", 0, "сид партии; если не задан, для каждой партии выбирается случайный")
+	flag.Parse()
+
 	game, err := src.NewGame()
 	if err != nil {
 		log.Fatal(err)
 	}
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "seed" {
+			game.SetSeed(*seed)
+		}
+	})
+	ebiten.SetTPS(engine.TPS)
 	ebiten.SetWindowSize(src.ScreenWidth, src.ScreenHeight)
 	ebiten.SetWindowTitle("Zetris")
 	if err := ebiten.RunGame(game); err != nil {